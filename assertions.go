@@ -0,0 +1,83 @@
+package golox
+
+import "fmt"
+
+// AssertionError is error of failed assertion
+var AssertionError = NewCustomError("AssertionError")
+
+// AssertFunc is assert(condition) native
+type AssertFunc struct{}
+
+// Call fails if condition is falsey
+func (af *AssertFunc) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	if !i.isTruthy(arguments[0]) {
+		return nil, AssertionError.New(nil, "Assertion failed.")
+	}
+	return nil, nil
+}
+
+// Arity returns 1
+func (af *AssertFunc) Arity() int {
+	return 1
+}
+
+func (af *AssertFunc) String() string {
+	return "<native fn>"
+}
+
+// AssertEqualFunc is assertEqual(expected, actual) native
+type AssertEqualFunc struct{}
+
+// Call fails if expected isn't equal to actual
+func (af *AssertEqualFunc) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	expected, actual := arguments[0], arguments[1]
+	if !isEqual(expected, actual) {
+		return nil, AssertionError.New(nil, fmt.Sprintf("Expected %s but got %s.", inspect(expected), inspect(actual)))
+	}
+	return nil, nil
+}
+
+// Arity returns 2
+func (af *AssertEqualFunc) Arity() int {
+	return 2
+}
+
+func (af *AssertEqualFunc) String() string {
+	return "<native fn>"
+}
+
+// AssertThrowsFunc is assertThrows(function) native
+type AssertThrowsFunc struct{}
+
+// Call calls function without arguments and fails unless it raises a runtime error
+func (af *AssertThrowsFunc) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	function, ok := arguments[0].(GoLoxCallable)
+	if !ok || function.Arity() != 0 {
+		return nil, RuntimeError.New(nil, "Argument of assertThrows must be a function without parameters.")
+	}
+
+	_, err := function.Call(i, nil)
+	if err == ErrTerminated {
+		return nil, err
+	}
+	if err == nil {
+		return nil, AssertionError.New(nil, "Expected a runtime error but nothing was thrown.")
+	}
+	return nil, nil
+}
+
+// Arity returns 1
+func (af *AssertThrowsFunc) Arity() int {
+	return 1
+}
+
+func (af *AssertThrowsFunc) String() string {
+	return "<native fn>"
+}
+
+// DefineAssertions defines assert, assertEqual and assertThrows natives
+func (r *Runtime) DefineAssertions() {
+	r.Globals.Define("assert", &AssertFunc{})
+	r.Globals.Define("assertEqual", &AssertEqualFunc{})
+	r.Globals.Define("assertThrows", &AssertThrowsFunc{})
+}
@@ -42,12 +42,26 @@ func (ap *AstPrinter) visitCallExpr(expr *Call) (interface{}, error) {
 	return callee + "(args " + strings.Join(args, "))"), nil
 }
 
+func (ap *AstPrinter) visitCompoundAssignExpr(expr *CompoundAssign) (interface{}, error) {
+	if expr.Postfix {
+		return ap.parenthesizeExpr("postfix "+expr.Operator.Lexeme, expr.Target)
+	}
+	return ap.parenthesizeExpr(expr.Operator.Lexeme, expr.Target, expr.Value)
+}
+
+func (ap *AstPrinter) visitConditionalExpr(expr *Conditional) (interface{}, error) {
+	return ap.parenthesizeExpr("?:", expr.Condition, expr.ThenBranch, expr.ElseBranch)
+}
+
 func (ap *AstPrinter) visitGetExpr(expr *Get) (interface{}, error) {
 	object, err := ap.parenthesizeExpr("object", expr.Object)
 	if err != nil {
 		return "", err
 	}
 
+	if expr.Optional {
+		return "(optional-get " + object + " (property " + expr.Name.Lexeme + ")", nil
+	}
 	return "(get " + object + " (property " + expr.Name.Lexeme + ")", nil
 }
 
@@ -55,6 +69,18 @@ func (ap *AstPrinter) visitGroupingExpr(expr *Grouping) (interface{}, error) {
 	return ap.parenthesizeExpr("group", expr.Expression)
 }
 
+func (ap *AstPrinter) visitIndexExpr(expr *Index) (interface{}, error) {
+	return ap.parenthesizeExpr("index", expr.Object, expr.Index)
+}
+
+func (ap *AstPrinter) visitInterpolationExpr(expr *Interpolation) (interface{}, error) {
+	return ap.parenthesizeExpr("interpolation", expr.Parts...)
+}
+
+func (ap *AstPrinter) visitListExpr(expr *List) (interface{}, error) {
+	return ap.parenthesizeExpr("list", expr.Elements...)
+}
+
 func (ap *AstPrinter) visitLiteralExpr(expr *Literal) (interface{}, error) {
 	if expr.Value == nil {
 		return "nil", nil
@@ -66,6 +92,38 @@ func (ap *AstPrinter) visitLogicalExpr(expr *Logical) (interface{}, error) {
 	return ap.parenthesizeExpr(expr.Operator.Lexeme, expr.Left, expr.Right)
 }
 
+func (ap *AstPrinter) visitLambdaExpr(expr *Lambda) (interface{}, error) {
+	params := make([]string, 0, len(expr.Function.Params))
+	for _, param := range expr.Function.Params {
+		params = append(params, param.Lexeme)
+	}
+	body, err := ap.parenthesizeStmt("body", expr.Function.Body...)
+	if err != nil {
+		return "", err
+	}
+	return "(lambda (args (" + strings.Join(params, ", ") + ")) " + body + ")", nil
+}
+
+func (ap *AstPrinter) visitMatchExpr(expr *Match) (interface{}, error) {
+	value, err := ap.parenthesizeExpr("value", expr.Value)
+	if err != nil {
+		return "", err
+	}
+	arms := make([]string, 0, len(expr.Arms))
+	for _, arm := range expr.Arms {
+		s, err := ap.parenthesizeExpr("arm "+arm.Pattern.String(), arm.Body)
+		if err != nil {
+			return "", err
+		}
+		arms = append(arms, s)
+	}
+	return "(match " + value + " " + strings.Join(arms, " ") + ")", nil
+}
+
+func (ap *AstPrinter) visitOptionalChainExpr(expr *OptionalChain) (interface{}, error) {
+	return ap.parenthesizeExpr("optional-chain", expr.Expression)
+}
+
 func (ap *AstPrinter) visitSetExpr(expr *Set) (interface{}, error) {
 	object, err := ap.parenthesizeExpr("object", expr.Object)
 	if err != nil {
@@ -80,6 +138,10 @@ func (ap *AstPrinter) visitSetExpr(expr *Set) (interface{}, error) {
 	return "(set " + object + "(name " + expr.Name.Lexeme + ")" + value + ")", nil
 }
 
+func (ap *AstPrinter) visitSetIndexExpr(expr *SetIndex) (interface{}, error) {
+	return ap.parenthesizeExpr("set-index", expr.Object, expr.Index, expr.Value)
+}
+
 func (ap *AstPrinter) visitSuperExpr(expr *Super) (interface{}, error) {
 	return "(super " + expr.Keyword.Lexeme + " " + expr.Method.Lexeme + ")", nil
 }
@@ -112,6 +174,21 @@ func (ap *AstPrinter) visitBlockStmt(b *Block) (interface{}, error) {
 	return "(block " + strings.Join(body, " ") + ")", nil
 }
 
+func (ap *AstPrinter) visitCaseStmt(c *Case) (interface{}, error) {
+	body, err := ap.parenthesizeStmt("body", c.Body...)
+	if err != nil {
+		return "", err
+	}
+	if c.Values == nil {
+		return "(default " + body + ")", nil
+	}
+	values, err := ap.parenthesizeExpr("values", c.Values...)
+	if err != nil {
+		return "", err
+	}
+	return "(case " + values + " " + body + ")", nil
+}
+
 func (ap *AstPrinter) visitClassStmt(c *Class) (interface{}, error) {
 	fns := make([]string, 0)
 	for _, method := range c.Methods {
@@ -195,6 +272,22 @@ func (ap *AstPrinter) visitWhileStmt(p *While) (interface{}, error) {
 	return "(while " + cond + " " + body + ")", nil
 }
 
+func (ap *AstPrinter) visitSwitchStmt(s *Switch) (interface{}, error) {
+	value, err := ap.parenthesizeExpr("value", s.Value)
+	if err != nil {
+		return "", err
+	}
+	cases := make([]Stmt, 0, len(s.Cases))
+	for _, c := range s.Cases {
+		cases = append(cases, c)
+	}
+	body, err := ap.parenthesizeStmt("cases", cases...)
+	if err != nil {
+		return "", err
+	}
+	return "(switch " + value + " " + body + ")", nil
+}
+
 func (ap *AstPrinter) visitVarStmt(v *Var) (interface{}, error) {
 	initializer, err := ap.parenthesizeExpr("initializer", v.Initializer)
 	if err != nil {
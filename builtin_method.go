@@ -0,0 +1,81 @@
+package golox
+
+import (
+	"fmt"
+	"math"
+)
+
+// BuiltinMethod is a method of built-in type, such as string and list, bound to its receiver
+type BuiltinMethod struct {
+	name     string
+	arity    int
+	function func(i *Interpreter, arguments []interface{}) (interface{}, error)
+}
+
+// Call calls the method
+func (bm *BuiltinMethod) Call(i *Interpreter, arguments []interface{}) (interface{}, error) {
+	return bm.function(i, arguments)
+}
+
+// Arity returns the number of parameters
+func (bm *BuiltinMethod) Arity() int {
+	return bm.arity
+}
+
+func (bm *BuiltinMethod) String() string {
+	return "<native fn>"
+}
+
+// stringArgument returns k-th argument of the method if it's a string
+func (bm *BuiltinMethod) stringArgument(arguments []interface{}, k int) (string, error) {
+	s, ok := arguments[k].(string)
+	if !ok {
+		return "", RuntimeError.New(nil, fmt.Sprintf("Argument %d of %s must be a string.", k+1, bm.name))
+	}
+	return s, nil
+}
+
+// integerArgument returns k-th argument of the method if it's an integral number
+func (bm *BuiltinMethod) integerArgument(arguments []interface{}, k int) (int, error) {
+	n, ok := toInteger(arguments[k])
+	if !ok {
+		if isIntegral(arguments[k]) {
+			return 0, RuntimeError.New(nil, fmt.Sprintf("Argument %d of %s is out of range: %s.", k+1, bm.name, stringfy(arguments[k])))
+		}
+		return 0, RuntimeError.New(nil, fmt.Sprintf("Argument %d of %s must be an integer.", k+1, bm.name))
+	}
+	return n, nil
+}
+
+// isIntegral reports whether value is a finite integral number
+func isIntegral(value interface{}) bool {
+	n, ok := value.(float64)
+	return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+}
+
+// toInteger converts value into int if it's an integral number in range of int
+func toInteger(value interface{}) (int, bool) {
+	if !isIntegral(value) {
+		return 0, false
+	}
+	n := value.(float64)
+	if n < math.MinInt || n >= math.MaxInt {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// checkIndex validates index of sequence whose length is length
+func checkIndex(bracket *Token, index interface{}, length int) (int, error) {
+	k, ok := toInteger(index)
+	if !ok {
+		if isIntegral(index) {
+			return 0, RuntimeError.New(bracket, fmt.Sprintf("Index %s is out of range for length %d.", stringfy(index), length))
+		}
+		return 0, RuntimeError.New(bracket, "Index must be an integer.")
+	}
+	if k < 0 || k >= length {
+		return 0, RuntimeError.New(bracket, fmt.Sprintf("Index %d is out of range for length %d.", k, length))
+	}
+	return k, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goropikari/golox"
+)
+
+const debugHelp = `Commands:
+  b[reak] [file:]line    set breakpoint
+  d[elete] [file:]line   delete breakpoint
+  c[ontinue]             run until the next breakpoint
+  s[tep]                 step into function call
+  n[ext]                 step over function call
+  o[ut]                  step out of current function
+  bt                     print call stack
+  l[ocals]               print variables of current environment chain
+  p[rint] name           print value of variable
+  q[uit]                 terminate program
+  h[elp]                 print this message`
+
+// runDebug runs the script under interactive debugger and returns exit status
+func runDebug(args []string) int {
+	if len(args) != 1 {
+		fmt.Println("Usage: golox debug script")
+		return 64
+	}
+
+	path := args[0]
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := golox.NewRuntime()
+	r.BasePath = filepath.Dir(path)
+	r.File = path
+
+	console := &debugConsole{file: path, stdin: bufio.NewReader(os.Stdin), out: os.Stdout}
+	debugger := golox.NewDebugger(console.pause)
+	debugger.Pause()
+	r.AddTracer(debugger)
+
+	fmt.Fprintln(console.out, "Type 'help' for commands.")
+	r.Run(bytes.NewBuffer(source))
+
+	if r.HadError {
+		return 65
+	}
+	if r.HadRuntimeError {
+		return 70
+	}
+	return 0
+}
+
+// debugConsole is line oriented user interface of golox.Debugger
+type debugConsole struct {
+	file  string
+	stdin *bufio.Reader
+	out   io.Writer
+}
+
+func (c *debugConsole) pause(d *golox.Debugger, reason string) golox.DebugAction {
+	frame := d.Frames()[0]
+	fmt.Fprintf(c.out, "Paused (%s) at %s:%d in %s\n", reason, frame.Position.File, frame.Position.Line, frame.Name)
+
+	for {
+		fmt.Fprint(c.out, "(debug) ")
+		line, err := c.stdin.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return golox.TerminateAction
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "b", "break", "d", "delete":
+			if len(fields) != 2 {
+				fmt.Fprintln(c.out, "usage: "+fields[0]+" [file:]line")
+				continue
+			}
+			file, lineNum, err := c.location(fields[1])
+			if err != nil {
+				fmt.Fprintln(c.out, err)
+				continue
+			}
+			if fields[0][0] == 'b' {
+				d.SetBreakpoint(file, lineNum)
+				fmt.Fprintf(c.out, "Breakpoint set at %s:%d\n", file, lineNum)
+			} else {
+				d.ClearBreakpoint(file, lineNum)
+				fmt.Fprintf(c.out, "Breakpoint deleted at %s:%d\n", file, lineNum)
+			}
+		case "c", "continue":
+			return golox.ContinueAction
+		case "s", "step":
+			return golox.StepIntoAction
+		case "n", "next":
+			return golox.StepOverAction
+		case "o", "out":
+			return golox.StepOutAction
+		case "q", "quit":
+			return golox.TerminateAction
+		case "bt":
+			for k, f := range d.Frames() {
+				fmt.Fprintf(c.out, "#%d %s at %s:%d\n", k, f.Name, f.Position.File, f.Position.Line)
+			}
+		case "l", "locals":
+			c.printEnvironment(frame.Environment)
+		case "p", "print":
+			if len(fields) != 2 {
+				fmt.Fprintln(c.out, "usage: print name")
+				continue
+			}
+			if v, ok := d.Lookup(frame, fields[1]); ok {
+				fmt.Fprintln(c.out, golox.Stringfy(v))
+			} else {
+				fmt.Fprintf(c.out, "Undefined variable '%s'.\n", fields[1])
+			}
+		case "h", "help":
+			fmt.Fprintln(c.out, debugHelp)
+		default:
+			fmt.Fprintf(c.out, "Unknown command '%s'. Type 'help' for commands.\n", fields[0])
+		}
+	}
+}
+
+// location parses [file:]line. file defaults to the script being debugged.
+func (c *debugConsole) location(s string) (string, int, error) {
+	file := c.file
+	if k := strings.LastIndex(s, ":"); k >= 0 {
+		file, s = s[:k], s[k+1:]
+	}
+	line, err := strconv.Atoi(s)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid line number: %s", s)
+	}
+	return file, line, nil
+}
+
+// printEnvironment prints variables from the innermost environment to globals
+func (c *debugConsole) printEnvironment(env *golox.Environment) {
+	for depth := 0; env != nil; depth, env = depth+1, env.Enclosing {
+		if env.Enclosing == nil {
+			fmt.Fprintln(c.out, "[globals]")
+		} else {
+			fmt.Fprintf(c.out, "[scope %d]\n", depth)
+		}
+		names := make([]string, 0, len(env.Values))
+		for name := range env.Values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(c.out, "  %s = %s\n", name, golox.Stringfy(env.Values[name]))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/goropikari/golox"
+)
+
+// runFmt formats given scripts and returns exit status
+func runFmt(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write result to source file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: golox fmt [-w] [script...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 64
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 66
+			continue
+		}
+
+		formatted, err := golox.Format(source)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, path+":")
+			fmt.Fprintln(os.Stderr, err)
+			status = 65
+			continue
+		}
+
+		if *write {
+			err = ioutil.WriteFile(path, formatted, 0644)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				status = 74
+			}
+		} else {
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+
+	"github.com/goropikari/golox"
+)
+
+// runLint reports warnings of given scripts and returns exit status
+func runLint(paths []string) int {
+	if len(paths) == 0 {
+		fmt.Println("Usage: golox lint [script...]")
+		return 64
+	}
+
+	status := 0
+	for _, path := range paths {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		r := golox.NewRuntime()
+		r.BasePath = filepath.Dir(path)
+		statements := r.Parse(bytes.NewBuffer(source))
+		if r.HadError {
+			status = 65
+			continue
+		}
+
+		for _, warning := range golox.NewLinter(r).Lint(statements) {
+			fmt.Printf("%s:%v\n", path, warning)
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+
+	return status
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -11,31 +12,107 @@ import (
 	"path/filepath"
 
 	"github.com/goropikari/golox"
+	"github.com/goropikari/golox/dap"
+	"github.com/goropikari/golox/lsp"
 )
 
 func main() {
+	if len(os.Args) >= 2 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:]))
+		case "test":
+			os.Exit(runTest(os.Args[2:]))
+		case "debug":
+			os.Exit(runDebug(os.Args[2:]))
+		case "dap":
+			if err := dap.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "lsp":
+			if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+
+	flags := flag.NewFlagSet("golox", flag.ExitOnError)
+	options := &runOptions{}
+	flags.StringVar(&options.profile, "profile", "", "write pprof profile to `file` and print profile report to stderr")
+	flags.StringVar(&options.coverage, "coverage", "", "write lcov coverage report to `file` and print coverage summary to stderr")
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
 	runtime := golox.NewRuntime()
 
-	if len(os.Args) > 2 {
-		fmt.Println("Usage: golox [script]")
+	if flags.NArg() > 1 {
+		usage()
 		os.Exit(64)
-	} else if len(os.Args) == 2 {
-		runFile(os.Args[1], runtime)
+	} else if flags.NArg() == 1 {
+		runFile(flags.Arg(0), runtime, options)
 	} else {
 		runPrompt(runtime)
 	}
 }
 
-func runFile(path string, r *golox.Runtime) {
+func usage() {
+	fmt.Println("Usage: golox [--profile file] [--coverage file] [script]")
+	fmt.Println("       golox lint [script...]")
+	fmt.Println("       golox fmt [-w] [script...]")
+	fmt.Println("       golox test [-v] [-junit file] [path...]")
+	fmt.Println("       golox debug script")
+	fmt.Println("       golox dap")
+	fmt.Println("       golox lsp")
+}
+
+// runOptions are options for running script
+type runOptions struct {
+	profile  string
+	coverage string
+}
+
+func runFile(path string, r *golox.Runtime, options *runOptions) {
 	source, err := ioutil.ReadFile(path)
 	if err != nil {
 		log.Fatal(err)
 	}
 	r.BasePath = filepath.Dir(path)
+	r.File = path
+
+	var profiler *golox.Profiler
+	if options.profile != "" {
+		profiler = golox.NewProfiler()
+		r.AddTracer(profiler)
+		profiler.Start()
+	}
+
+	var coverage *golox.Coverage
+	if options.coverage != "" {
+		coverage = golox.NewCoverage(r)
+		r.AddTracer(coverage)
+	}
 
 	// fmt.Println(source)
 	r.Run(bytes.NewBuffer(source))
 
+	if profiler != nil {
+		profiler.Stop()
+		if err := writeProfile(profiler, options.profile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if coverage != nil {
+		if err := writeCoverage(coverage, options.coverage); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if r.HadError {
 		os.Exit(65)
 	}
@@ -44,6 +121,40 @@ func runFile(path string, r *golox.Runtime) {
 	}
 }
 
+// writeProfile writes pprof profile to path and text report to stderr
+func writeProfile(profiler *golox.Profiler, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := profiler.WritePprof(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return profiler.WriteText(os.Stderr)
+}
+
+// writeCoverage writes lcov report to path and summary to stderr
+func writeCoverage(coverage *golox.Coverage, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := coverage.WriteLcov(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return coverage.WriteSummary(os.Stderr)
+}
+
 func runPrompt(r *golox.Runtime) {
 	stdin := bufio.NewReader(os.Stdin)
 	buf := &bytes.Buffer{}
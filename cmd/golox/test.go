@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goropikari/golox"
+)
+
+// runTest runs *_test.lox files in given files or directories and returns exit status
+func runTest(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	verbose := flags.Bool("v", false, "print passed tests and output of all tests")
+	junit := flags.String("junit", "", "write JUnit XML report to `file`")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: golox test [-v] [-junit file] [path...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := findTestFiles(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 66
+	}
+
+	results := make([]*golox.TestResult, 0)
+	for _, file := range files {
+		rs, err := golox.RunTestFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 66
+		}
+		results = append(results, rs...)
+	}
+
+	passed, failed := 0, 0
+	for _, result := range results {
+		if result.Passed {
+			passed++
+			if *verbose {
+				fmt.Printf("--- PASS: %s (%s)\n", result.Name, result.File)
+				printIndented(result.Output)
+			}
+			continue
+		}
+
+		failed++
+		fmt.Printf("--- FAIL: %s (%s:%d)\n", result.Name, result.File, result.Line)
+		printIndented(result.Message)
+		printIndented(result.Output)
+	}
+
+	if *junit != "" {
+		f, err := os.Create(*junit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 66
+		}
+		err = golox.WriteJUnit(f, results)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 66
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("FAIL: %d passed, %d failed\n", passed, failed)
+		return 1
+	}
+	fmt.Printf("ok: %d passed\n", passed)
+	return 0
+}
+
+// findTestFiles returns given files and *_test.lox files under given directories
+func findTestFiles(paths []string) ([]string, error) {
+	files := make([]string, 0)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(p, "_test.lox") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func printIndented(text string) {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Println("    " + line)
+	}
+}
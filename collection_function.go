@@ -0,0 +1,275 @@
+package golox
+
+import "sort"
+
+// CollectionFunc is a native which takes lists and calls back Lox functions
+type CollectionFunc struct {
+	name     string
+	arity    int
+	function func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error)
+}
+
+// map(list, f)
+// ex. map([1, 2], x => x * 2) // [2, 4]
+
+// NewMapFunc returns a list of f applied to each element
+func NewMapFunc() *CollectionFunc {
+	return &CollectionFunc{name: "map", arity: 2, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, f, err := cf.listAndFunction(ctx, arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		elements := make([]interface{}, 0, len(list.Elements))
+		for _, element := range list.Elements {
+			value, err := ctx.Call(f, element)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, value)
+		}
+		return NewGoLoxList(elements), nil
+	}}
+}
+
+// filter(list, f)
+// ex. filter([1, 2, 3], x => x % 2 == 1) // [1, 3]
+
+// NewFilterFunc returns a list of elements for which f returns truthy value
+func NewFilterFunc() *CollectionFunc {
+	return &CollectionFunc{name: "filter", arity: 2, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, f, err := cf.listAndFunction(ctx, arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		elements := make([]interface{}, 0)
+		for _, element := range list.Elements {
+			ok, err := ctx.Call(f, element)
+			if err != nil {
+				return nil, err
+			}
+			if ctx.IsTruthy(ok) {
+				elements = append(elements, element)
+			}
+		}
+		return NewGoLoxList(elements), nil
+	}}
+}
+
+// reduce(list, f, initial)
+// ex. reduce([1, 2, 3], (acc, x) => acc + x, 0) // 6
+
+// NewReduceFunc folds the list from left with f starting from initial
+func NewReduceFunc() *CollectionFunc {
+	return &CollectionFunc{name: "reduce", arity: 3, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, f, err := cf.listAndFunction(ctx, arguments, 2)
+		if err != nil {
+			return nil, err
+		}
+		accumulator := arguments[2]
+		for _, element := range list.Elements {
+			accumulator, err = ctx.Call(f, accumulator, element)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return accumulator, nil
+	}}
+}
+
+// forEach(list, f)
+// ex. forEach([1, 2], x => print x)
+
+// NewForEachFunc calls f with each element
+func NewForEachFunc() *CollectionFunc {
+	return &CollectionFunc{name: "forEach", arity: 2, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, f, err := cf.listAndFunction(ctx, arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range list.Elements {
+			if _, err := ctx.Call(f, element); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}}
+}
+
+// sort(list, cmp)
+// ex. sort([3, 1, 2], (a, b) => a - b) // [1, 2, 3]
+
+// NewSortFunc returns a sorted copy of the list.
+// cmp(a, b) returns a negative number if a is less than b. The sort is stable.
+func NewSortFunc() *CollectionFunc {
+	return &CollectionFunc{name: "sort", arity: 2, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, cmp, err := cf.listAndFunction(ctx, arguments, 2)
+		if err != nil {
+			return nil, err
+		}
+		elements := make([]interface{}, len(list.Elements))
+		copy(elements, list.Elements)
+
+		// the first error stops further comparisons
+		var cmpErr error
+		sort.SliceStable(elements, func(a, b int) bool {
+			if cmpErr != nil {
+				return false
+			}
+			value, err := ctx.Call(cmp, elements[a], elements[b])
+			if err != nil {
+				cmpErr = err
+				return false
+			}
+			n, ok := value.(float64)
+			if !ok {
+				cmpErr = ctx.Error("Comparator of sort must return a number but got %s.", inspect(value))
+				return false
+			}
+			return n < 0
+		})
+		if cmpErr != nil {
+			return nil, cmpErr
+		}
+		return NewGoLoxList(elements), nil
+	}}
+}
+
+// range(n)
+// ex. range(3) // [0, 1, 2]
+
+// maxRangeLength is the largest n of range(n) so that a typo can't exhaust memory
+const maxRangeLength = 10000000
+
+// NewRangeFunc returns a list of integers from 0 to n - 1
+func NewRangeFunc() *CollectionFunc {
+	return &CollectionFunc{name: "range", arity: 1, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		if !isIntegral(arguments[0]) {
+			return nil, ctx.Error("Argument 1 of range must be an integer but got %s.", inspect(arguments[0]))
+		}
+		if n := arguments[0].(float64); n > maxRangeLength {
+			return nil, ctx.Error("Argument 1 of range must be at most %d but got %s.", maxRangeLength, inspect(n))
+		}
+		n, _ := toInteger(arguments[0])
+		elements := make([]interface{}, 0)
+		for k := 0; k < n; k++ {
+			elements = append(elements, float64(k))
+		}
+		return NewGoLoxList(elements), nil
+	}}
+}
+
+// zip(a, b)
+// ex. zip([1, 2], ["a", "b", "c"]) // [[1, "a"], [2, "b"]]
+
+// NewZipFunc returns a list of pairs of elements at the same index. It's as long as the shorter list.
+func NewZipFunc() *CollectionFunc {
+	return &CollectionFunc{name: "zip", arity: 2, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		a, err := cf.listArgument(ctx, arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		b, err := cf.listArgument(ctx, arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		n := len(a.Elements)
+		if len(b.Elements) < n {
+			n = len(b.Elements)
+		}
+		elements := make([]interface{}, 0, n)
+		for k := 0; k < n; k++ {
+			elements = append(elements, NewGoLoxList([]interface{}{a.Elements[k], b.Elements[k]}))
+		}
+		return NewGoLoxList(elements), nil
+	}}
+}
+
+// enumerate(list)
+// ex. enumerate(["a", "b"]) // [[0, "a"], [1, "b"]]
+
+// NewEnumerateFunc returns a list of pairs of index and element
+func NewEnumerateFunc() *CollectionFunc {
+	return &CollectionFunc{name: "enumerate", arity: 1, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, err := cf.listArgument(ctx, arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		elements := make([]interface{}, 0, len(list.Elements))
+		for k, element := range list.Elements {
+			elements = append(elements, NewGoLoxList([]interface{}{float64(k), element}))
+		}
+		return NewGoLoxList(elements), nil
+	}}
+}
+
+// any(list, f)
+// ex. any([1, 2], x => x > 1) // true
+
+// NewAnyFunc reports whether f returns truthy value for some element. It stops at the first one.
+func NewAnyFunc() *CollectionFunc {
+	return newQuantifierFunc("any", true)
+}
+
+// all(list, f)
+// ex. all([1, 2], x => x > 1) // false
+
+// NewAllFunc reports whether f returns truthy value for every element. It stops at the first falsey one.
+func NewAllFunc() *CollectionFunc {
+	return newQuantifierFunc("all", false)
+}
+
+// newQuantifierFunc returns a native which returns stopAt as soon as truthiness of f equals stopAt
+func newQuantifierFunc(name string, stopAt bool) *CollectionFunc {
+	return &CollectionFunc{name: name, arity: 2, function: func(cf *CollectionFunc, ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+		list, f, err := cf.listAndFunction(ctx, arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range list.Elements {
+			value, err := ctx.Call(f, element)
+			if err != nil {
+				return nil, err
+			}
+			if ctx.IsTruthy(value) == stopAt {
+				return stopAt, nil
+			}
+		}
+		return !stopAt, nil
+	}}
+}
+
+// Call calls the native
+func (cf *CollectionFunc) Call(ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+	return cf.function(cf, ctx, arguments)
+}
+
+// Arity returns the number of parameters
+func (cf *CollectionFunc) Arity() int {
+	return cf.arity
+}
+
+// listArgument returns k-th argument if it's a list
+func (cf *CollectionFunc) listArgument(ctx *NativeContext, arguments []interface{}, k int) (*GoLoxList, error) {
+	list, ok := arguments[k].(*GoLoxList)
+	if !ok {
+		return nil, ctx.Error("Argument %d of %s must be a list but got %s.", k+1, cf.name, inspect(arguments[k]))
+	}
+	return list, nil
+}
+
+// listAndFunction returns the first argument as a list and the second as a function taking arity arguments
+func (cf *CollectionFunc) listAndFunction(ctx *NativeContext, arguments []interface{}, arity int) (*GoLoxList, GoLoxCallable, error) {
+	list, err := cf.listArgument(ctx, arguments, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, ok := arguments[1].(GoLoxCallable)
+	if !ok || f.Arity() != arity {
+		parameters := "parameters"
+		if arity == 1 {
+			parameters = "parameter"
+		}
+		return nil, nil, ctx.Error("Argument 2 of %s must be a function with %d %s but got %s.", cf.name, arity, parameters, inspect(arguments[1]))
+	}
+	return list, f, nil
+}
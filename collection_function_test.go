@@ -0,0 +1,64 @@
+package golox_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionNatives(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "map", expected: "[2, 4, 6]\n", given: "print map([1, 2, 3], x => x * 2);"},
+		{name: "map with native", expected: "[\"1\", \"2\"]\n", given: "print map([1, 2], str);"},
+		{name: "filter", expected: "[1, 3]\n", given: "print filter([1, 2, 3], x => x % 2 == 1);"},
+		{name: "reduce", expected: "6\n", given: "print reduce([1, 2, 3], (acc, x) => acc + x, 0);"},
+		{name: "reduce of empty list", expected: "init\n", given: `print reduce([], (acc, x) => acc + x, "init");`},
+		{name: "forEach", expected: "a\nb\nnil\n", given: `print forEach(["a", "b"], fun (x) { print x; });`},
+		{name: "sort", expected: "[1, 2, 3]\n[3, 1, 2]\n", given: "var xs = [3, 1, 2]; print sort(xs, (a, b) => a - b); print xs;"},
+		{name: "sort is stable", expected: "[[1, \"a\"], [1, \"b\"], [2, \"c\"]]\n", given: `print sort([[2, "c"], [1, "a"], [1, "b"]], (a, b) => a[0] - b[0]);`},
+		{name: "range", expected: "[0, 1, 2]\n[]\n", given: "print range(3); print range(0);"},
+		{name: "zip truncates to shorter list", expected: "[[1, \"a\"], [2, \"b\"]]\n", given: `print zip([1, 2], ["a", "b", "c"]);`},
+		{name: "enumerate", expected: "[[0, \"a\"], [1, \"b\"]]\n", given: `print enumerate(["a", "b"]);`},
+		{name: "any", expected: "true\nfalse\n", given: "print any([1, 2], x => x > 1); print any([], x => true);"},
+		{name: "all", expected: "false\ntrue\n", given: "print all([1, 2], x => x > 1); print all([], x => false);"},
+		{name: "any stops at first match", expected: "1\n2\ntrue\n", given: "print any([1, 2, 3], fun (x) { print x; return x == 2; });"},
+		{name: "closure", expected: "[11, 12]\n", given: "var n = 10; print map([1, 2], x => x + n);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestCollectionNatives_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "map of string", expected: "Argument 1 of map must be a list but got \"ab\".\n[line 1]\n", given: `map("ab", x => x);`},
+		{name: "filter with non function", expected: "Argument 2 of filter must be a function with 1 parameter but got nil.\n[line 1]\n", given: "filter([1], nil);"},
+		{name: "reduce with wrong arity", expected: "Argument 2 of reduce must be a function with 2 parameters but got <fn>.\n[line 1]\n", given: "reduce([1], x => x, 0);"},
+		{name: "sort with non number comparator", expected: "Comparator of sort must return a number but got \"x\".\n[line 1]\n", given: `sort([1, 2], (a, b) => "x");`},
+		{name: "range of fraction", expected: "Argument 1 of range must be an integer but got 1.5.\n[line 1]\n", given: "range(1.5);"},
+		{name: "range too long", expected: "Argument 1 of range must be at most 10000000 but got 10000000000.\n[line 1]\n", given: "var r = range(1e10);"},
+		{name: "error in callback", expected: "Operand must be a number.\n[line 2]\n", given: "map([1, \"a\"],\n  x => -x);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
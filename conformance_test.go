@@ -0,0 +1,245 @@
+package golox_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/golox"
+)
+
+// conformanceDirEnv points at a checkout of the test directory of munificent/craftinginterpreters.
+// If it is not set, the vendored cases in testdata/conformance are used.
+const conformanceDirEnv = "LOX_CONFORMANCE_DIR"
+
+// Exit codes used by jlox
+const (
+	exitOK           = 0
+	exitCompileError = 65
+	exitRuntimeError = 70
+)
+
+// Annotations of the Crafting Interpreters test suite
+var (
+	expectedOutputPattern       = regexp.MustCompile(`// expect: ?(.*)`)
+	expectedErrorPattern        = regexp.MustCompile(`// (Error.*)`)
+	errorLinePattern            = regexp.MustCompile(`// \[((java|c) )?line (\d+)\] (Error.*)`)
+	expectedRuntimeErrorPattern = regexp.MustCompile(`// expect runtime error: (.+)`)
+	nonTestPattern              = regexp.MustCompile(`// nontest`)
+)
+
+// skippedConformanceDirs are directories of the upstream suite which don't target jlox
+var skippedConformanceDirs = map[string]bool{
+	"benchmark":   true,
+	"expressions": true,
+	"limit":       true,
+	"scanning":    true,
+}
+
+// divergences are cases where golox intentionally behaves differently from jlox
+var divergences = map[string]string{
+	"number/leading_dot.lox":   "golox reports a leading dot of number literal in the scanner.",
+	"unexpected_character.lox": "'|' is the bitwise or operator in golox.",
+}
+
+// conformanceCase is expectation of a .lox file
+type conformanceCase struct {
+	output       []string
+	errors       []string
+	runtimeError string
+	runtimeLine  int
+	exitCode     int
+	nonTest      bool
+}
+
+func parseConformanceCase(source string) *conformanceCase {
+	c := &conformanceCase{output: make([]string, 0), errors: make([]string, 0), exitCode: exitOK}
+	for k, line := range strings.Split(source, "\n") {
+		lineNum := k + 1
+
+		if nonTestPattern.MatchString(line) {
+			c.nonTest = true
+		}
+		if m := expectedOutputPattern.FindStringSubmatch(line); m != nil {
+			c.output = append(c.output, m[1])
+			continue
+		}
+		if m := expectedErrorPattern.FindStringSubmatch(line); m != nil {
+			c.errors = append(c.errors, fmt.Sprintf("[line %d] %s", lineNum, m[1]))
+			c.exitCode = exitCompileError
+			continue
+		}
+		if m := errorLinePattern.FindStringSubmatch(line); m != nil {
+			// errors of clox only
+			if m[2] == "c" {
+				continue
+			}
+			c.errors = append(c.errors, fmt.Sprintf("[line %s] %s", m[3], m[4]))
+			c.exitCode = exitCompileError
+			continue
+		}
+		if m := expectedRuntimeErrorPattern.FindStringSubmatch(line); m != nil {
+			c.runtimeError = m[1]
+			c.runtimeLine = lineNum
+			c.exitCode = exitRuntimeError
+		}
+	}
+	return c
+}
+
+// runConformanceCase runs the file like `golox file` and returns stdout, stderr and exit code
+func runConformanceCase(path string, source []byte) (string, string, int) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stdout = stdout
+	r.Stderr = stderr
+	r.BasePath = filepath.Dir(path)
+	r.File = path
+	r.Run(bytes.NewBuffer(source))
+
+	exitCode := exitOK
+	if r.HadError {
+		exitCode = exitCompileError
+	} else if r.HadRuntimeError {
+		exitCode = exitRuntimeError
+	}
+	return stdout.String(), stderr.String(), exitCode
+}
+
+// verify returns failures of actual result against expectation
+func (c *conformanceCase) verify(stdout, stderr string, exitCode int) []string {
+	failures := make([]string, 0)
+	errorLines := splitLines(stderr)
+
+	switch {
+	case c.runtimeError != "":
+		if len(errorLines) < 2 {
+			failures = append(failures, fmt.Sprintf("Expected runtime error %q and got:\n%s", c.runtimeError, stderr))
+			break
+		}
+		if errorLines[0] != c.runtimeError {
+			failures = append(failures, fmt.Sprintf("Expected runtime error %q and got %q.", c.runtimeError, errorLines[0]))
+		}
+		if expected := fmt.Sprintf("[line %d]", c.runtimeLine); errorLines[1] != expected {
+			failures = append(failures, fmt.Sprintf("Expected %q on stderr and got %q.", expected, errorLines[1]))
+		}
+	default:
+		expected := make(map[string]bool)
+		for _, e := range c.errors {
+			expected[e] = true
+		}
+		for _, line := range errorLines {
+			if expected[line] {
+				delete(expected, line)
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("Unexpected error: %s", line))
+		}
+		missing := make([]string, 0)
+		for e := range expected {
+			missing = append(missing, e)
+		}
+		sort.Strings(missing)
+		for _, e := range missing {
+			failures = append(failures, fmt.Sprintf("Missing expected error: %s", e))
+		}
+	}
+
+	if exitCode != c.exitCode {
+		failures = append(failures, fmt.Sprintf("Expected return code %d and got %d.", c.exitCode, exitCode))
+	}
+
+	outputLines := splitLines(stdout)
+	for k, line := range outputLines {
+		if k >= len(c.output) {
+			failures = append(failures, fmt.Sprintf("Got output %q when none was expected.", line))
+			continue
+		}
+		if line != c.output[k] {
+			failures = append(failures, fmt.Sprintf("Expected output %q and got %q.", c.output[k], line))
+		}
+	}
+	for k := len(outputLines); k < len(c.output); k++ {
+		failures = append(failures, fmt.Sprintf("Missing expected output %q.", c.output[k]))
+	}
+	return failures
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+func conformanceFiles(t *testing.T, dir string) []string {
+	files := make([]string, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if skippedConformanceDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".lox" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestConformance runs .lox files annotated in the format of the Crafting Interpreters test suite
+// and compares stdout, stderr and exit code with the annotations.
+func TestConformance(t *testing.T) {
+	dir := os.Getenv(conformanceDirEnv)
+	if dir == "" {
+		dir = filepath.Join("testdata", "conformance")
+	}
+
+	passed, total := 0, 0
+	for _, path := range conformanceFiles(t, dir) {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := parseConformanceCase(string(source))
+		if c.nonTest {
+			continue
+		}
+
+		name, _ := filepath.Rel(dir, path)
+		name = filepath.ToSlash(name)
+		if reason, ok := divergences[name]; ok {
+			t.Run(name, func(t *testing.T) { t.Skip(reason) })
+			continue
+		}
+
+		total++
+		ok := t.Run(name, func(t *testing.T) {
+			stdout, stderr, exitCode := runConformanceCase(path, source)
+			for _, failure := range c.verify(stdout, stderr, exitCode) {
+				t.Error(failure)
+			}
+		})
+		if ok {
+			passed++
+		}
+	}
+	if total > 0 {
+		t.Logf("conformance: %d/%d passed (%.1f%%)", passed, total, 100*float64(passed)/float64(total))
+	}
+}
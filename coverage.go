@@ -0,0 +1,154 @@
+package golox
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Coverage records which statements are executed.
+// Register it by Runtime.AddTracer before Runtime.Run.
+// Statements of every file parsed by the runtime, including included files, are reported.
+type Coverage struct {
+	runtime *Runtime
+	counts  map[Stmt]int
+}
+
+// FileCoverage is line coverage of a source file
+type FileCoverage struct {
+	File string
+	// Lines maps executable line to its execution count
+	Lines map[int]int
+}
+
+// NewCoverage is constructor of Coverage
+func NewCoverage(runtime *Runtime) *Coverage {
+	return &Coverage{
+		runtime: runtime,
+		counts:  make(map[Stmt]int),
+	}
+}
+
+// Statement counts execution of the statement
+func (c *Coverage) Statement(i *Interpreter, stmt Stmt) error {
+	c.counts[stmt]++
+	return nil
+}
+
+// Call does nothing
+func (c *Coverage) Call(i *Interpreter, function *GoLoxFunction) {}
+
+// Return does nothing
+func (c *Coverage) Return(i *Interpreter, function *GoLoxFunction) {}
+
+// Files returns line coverage of each file sorted by file name.
+// A line is executable if a statement starts at it, and its count is the maximum count of the statements.
+func (c *Coverage) Files() []*FileCoverage {
+	// methods and lambdas are never executed as statements. Their bodies are.
+	methods := make(map[Stmt]bool)
+	for stmt := range c.runtime.Positions {
+		if class, ok := stmt.(*Class); ok {
+			for _, method := range class.Methods {
+				methods[method] = true
+			}
+		}
+		if function, ok := stmt.(*Function); ok && function.Name == nil {
+			methods[function] = true
+		}
+	}
+
+	files := make(map[string]*FileCoverage)
+	for stmt, pos := range c.runtime.Positions {
+		if methods[stmt] {
+			continue
+		}
+		// a block is covered by its statements
+		if _, ok := c.runtime.ForLoops[stmt]; !ok && stmt.IsType(&Block{}) {
+			continue
+		}
+
+		file, ok := files[pos.File]
+		if !ok {
+			file = &FileCoverage{File: pos.File, Lines: make(map[int]int)}
+			files[pos.File] = file
+		}
+		if count := c.counts[stmt]; count > file.Lines[pos.Line] {
+			file.Lines[pos.Line] = count
+		} else if _, ok := file.Lines[pos.Line]; !ok {
+			file.Lines[pos.Line] = 0
+		}
+	}
+
+	result := make([]*FileCoverage, 0, len(files))
+	for _, file := range files {
+		result = append(result, file)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].File < result[j].File })
+	return result
+}
+
+// Covered returns the number of executed lines and executable lines
+func (fc *FileCoverage) Covered() (int, int) {
+	hit := 0
+	for _, count := range fc.Lines {
+		if count > 0 {
+			hit++
+		}
+	}
+	return hit, len(fc.Lines)
+}
+
+// Percent returns percentage of executed lines
+func (fc *FileCoverage) Percent() float64 {
+	hit, found := fc.Covered()
+	return percent(hit, found)
+}
+
+// WriteLcov writes report in lcov tracefile format
+func (c *Coverage) WriteLcov(w io.Writer) error {
+	var b strings.Builder
+	for _, file := range c.Files() {
+		lines := make([]int, 0, len(file.Lines))
+		for line := range file.Lines {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+
+		b.WriteString("TN:\n")
+		b.WriteString("SF:" + displayFile(file.File) + "\n")
+		for _, line := range lines {
+			fmt.Fprintf(&b, "DA:%d,%d\n", line, file.Lines[line])
+		}
+		hit, found := file.Covered()
+		fmt.Fprintf(&b, "LF:%d\n", found)
+		fmt.Fprintf(&b, "LH:%d\n", hit)
+		b.WriteString("end_of_record\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteSummary writes coverage percentage of each file and total
+func (c *Coverage) WriteSummary(w io.Writer) error {
+	var b strings.Builder
+	totalHit, totalFound := 0, 0
+	for _, file := range c.Files() {
+		hit, found := file.Covered()
+		totalHit += hit
+		totalFound += found
+		fmt.Fprintf(&b, "%s: %.1f%% of lines (%d/%d)\n", displayFile(file.File), percent(hit, found), hit, found)
+	}
+	fmt.Fprintf(&b, "coverage: %.1f%% of lines (%d/%d)\n", percent(totalHit, totalFound), totalHit, totalFound)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func percent(hit, found int) float64 {
+	if found == 0 {
+		return 100
+	}
+	return 100 * float64(hit) / float64(found)
+}
@@ -0,0 +1,96 @@
+package golox_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+const coveree = `include "lib.lox";
+class A {
+  used() { return 1; }
+  unused() { return 2; }
+}
+if (A().used() > 0) print "yes";
+else print "no";
+var i = 0;
+while (i < 2) {
+  i = i + 1;
+}
+`
+
+const coverageLib = `fun twice(x) {
+  return 2 * x;
+}
+fun never() {
+  return 0;
+}
+print twice(2);
+`
+
+func cover(t *testing.T) (*golox.Coverage, string) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.lox")
+	assert.NoError(t, ioutil.WriteFile(main, []byte(coveree), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "lib.lox"), []byte(coverageLib), 0644))
+
+	r := golox.NewRuntime()
+	r.Stdout = &bytes.Buffer{}
+	r.BasePath = dir
+	r.File = main
+
+	coverage := golox.NewCoverage(r)
+	r.AddTracer(coverage)
+	r.Run(bytes.NewBufferString(coveree))
+
+	return coverage, dir
+}
+
+func TestCoverage_Files(t *testing.T) {
+	coverage, dir := cover(t)
+
+	files := coverage.Files()
+	assert.Equal(t, 2, len(files))
+
+	assert.Equal(t, filepath.Join(dir, "lib.lox"), files[0].File)
+	assert.Equal(t, map[int]int{1: 1, 2: 1, 4: 1, 5: 0, 7: 1}, files[0].Lines)
+
+	assert.Equal(t, filepath.Join(dir, "main.lox"), files[1].File)
+	assert.Equal(t, map[int]int{1: 1, 2: 1, 3: 1, 4: 0, 6: 1, 7: 0, 8: 1, 9: 1, 10: 2}, files[1].Lines)
+
+	hit, found := files[1].Covered()
+	assert.Equal(t, 7, hit)
+	assert.Equal(t, 9, found)
+	assert.InDelta(t, 77.8, files[1].Percent(), 0.1)
+}
+
+func TestCoverage_WriteLcov(t *testing.T) {
+	coverage, dir := cover(t)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, coverage.WriteLcov(buf))
+	expected := "TN:\n" +
+		"SF:" + filepath.ToSlash(filepath.Join(dir, "lib.lox")) + "\n" +
+		"DA:1,1\nDA:2,1\nDA:4,1\nDA:5,0\nDA:7,1\n" +
+		"LF:5\nLH:4\nend_of_record\n" +
+		"TN:\n" +
+		"SF:" + filepath.ToSlash(filepath.Join(dir, "main.lox")) + "\n" +
+		"DA:1,1\nDA:2,1\nDA:3,1\nDA:4,0\nDA:6,1\nDA:7,0\nDA:8,1\nDA:9,1\nDA:10,2\n" +
+		"LF:9\nLH:7\nend_of_record\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestCoverage_WriteSummary(t *testing.T) {
+	coverage, dir := cover(t)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, coverage.WriteSummary(buf))
+	expected := filepath.ToSlash(filepath.Join(dir, "lib.lox")) + ": 80.0% of lines (4/5)\n" +
+		filepath.ToSlash(filepath.Join(dir, "main.lox")) + ": 77.8% of lines (7/9)\n" +
+		"coverage: 78.6% of lines (11/14)\n"
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,439 @@
+// Package dap implements Debug Adapter Protocol server for Lox.
+package dap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goropikari/golox"
+)
+
+// Server is a debug adapter speaking DAP over given streams.
+// The program runs in its own goroutine and is paused by golox.Debugger.
+type Server struct {
+	reader   *bufio.Reader
+	writer   io.Writer
+	writeMu  sync.Mutex
+	seq      int
+	debugger *golox.Debugger
+	launch   *launchArguments
+	resume   chan golox.DebugAction
+	done     chan struct{}
+
+	// mu guards the state below which is shared with the program goroutine
+	mu          sync.Mutex
+	started     bool
+	running     bool
+	paused      bool
+	terminating bool
+	entry       bool
+	frames      []*golox.Frame
+	references  []interface{}
+}
+
+// NewServer is constructor of Server
+func NewServer(r io.Reader, w io.Writer) *Server {
+	s := &Server{
+		reader:      bufio.NewReader(r),
+		writer:      w,
+		seq:         0,
+		launch:      nil,
+		resume:      make(chan golox.DebugAction),
+		done:        make(chan struct{}),
+		started:     false,
+		running:     false,
+		paused:      false,
+		terminating: false,
+		entry:       false,
+		frames:      make([]*golox.Frame, 0),
+		references:  make([]interface{}, 0),
+	}
+	s.debugger = golox.NewDebugger(s.pause)
+	return s
+}
+
+// Serve handles requests until the client sends disconnect request or closes the stream.
+func (s *Server) Serve() error {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			s.terminate()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+
+		result, err := s.handle(&req)
+		if err != nil {
+			s.write(&response{Type: "response", RequestSeq: req.Seq, Success: false, Command: req.Command, Message: err.Error()})
+		} else {
+			s.write(&response{Type: "response", RequestSeq: req.Seq, Success: true, Command: req.Command, Body: result})
+		}
+
+		switch req.Command {
+		case "initialize":
+			s.write(&event{Type: "event", Event: "initialized"})
+		case "disconnect":
+			return nil
+		}
+	}
+}
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]interface{}{
+			"supportsConfigurationDoneRequest": true,
+		}, nil
+	case "launch":
+		var args launchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if args.Program == "" {
+			return nil, errors.New("program is not specified")
+		}
+		s.launch = &args
+		return nil, nil
+	case "setBreakpoints":
+		var args setBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"breakpoints": s.setBreakpoints(args)}, nil
+	case "configurationDone":
+		if s.launch == nil {
+			return nil, errors.New("launch request is required")
+		}
+		return nil, s.start()
+	case "threads":
+		return map[string]interface{}{"threads": []Thread{{ID: threadID, Name: "main"}}}, nil
+	case "stackTrace":
+		frames, err := s.stackTrace()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)}, nil
+	case "scopes":
+		var args scopesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		scopes, err := s.scopes(args.FrameID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"scopes": scopes}, nil
+	case "variables":
+		var args variablesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		variables, err := s.variables(args.VariablesReference)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"variables": variables}, nil
+	case "continue":
+		if err := s.resumeWith(golox.ContinueAction); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"allThreadsContinued": true}, nil
+	case "next":
+		return nil, s.resumeWith(golox.StepOverAction)
+	case "stepIn":
+		return nil, s.resumeWith(golox.StepIntoAction)
+	case "stepOut":
+		return nil, s.resumeWith(golox.StepOutAction)
+	case "pause":
+		s.debugger.Pause()
+		return nil, nil
+	case "disconnect":
+		s.terminate()
+		return nil, nil
+	}
+
+	return nil, errors.New("unsupported command: " + req.Command)
+}
+
+func (s *Server) setBreakpoints(args setBreakpointsArguments) []Breakpoint {
+	s.debugger.ClearBreakpoints(args.Source.Path)
+	breakpoints := make([]Breakpoint, 0)
+	for _, bp := range args.Breakpoints {
+		s.debugger.SetBreakpoint(args.Source.Path, bp.Line)
+		breakpoints = append(breakpoints, Breakpoint{Verified: true, Line: bp.Line})
+	}
+	return breakpoints
+}
+
+// start runs the program in a new goroutine. The program runs at most once per session.
+func (s *Server) start() error {
+	s.mu.Lock()
+	if s.started || s.running {
+		s.mu.Unlock()
+		return errors.New("program has already started")
+	}
+	s.started = true
+	s.mu.Unlock()
+
+	path := s.launch.Program
+	r := golox.NewRuntime()
+	r.BasePath = filepath.Dir(path)
+	r.File = path
+	r.Stdout = &outputWriter{server: s, category: "stdout"}
+	r.Stderr = &outputWriter{server: s, category: "stderr"}
+	r.AddTracer(s.debugger)
+
+	s.mu.Lock()
+	s.running = true
+	s.entry = s.launch.StopOnEntry
+	s.mu.Unlock()
+	if s.launch.StopOnEntry {
+		s.debugger.Pause()
+	}
+
+	go func() {
+		defer close(s.done)
+
+		exitCode := 0
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			s.output("stderr", err.Error()+"\n")
+			exitCode = 1
+		} else {
+			r.Run(bytes.NewBuffer(source))
+			if r.HadError {
+				exitCode = 65
+			} else if r.HadRuntimeError {
+				exitCode = 70
+			}
+		}
+
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+		s.write(&event{Type: "event", Event: "exited", Body: map[string]int{"exitCode": exitCode}})
+		s.write(&event{Type: "event", Event: "terminated"})
+	}()
+	return nil
+}
+
+// pause is called in the program goroutine and blocks until the client resumes it
+func (s *Server) pause(d *golox.Debugger, reason string) golox.DebugAction {
+	s.mu.Lock()
+	if s.terminating {
+		s.mu.Unlock()
+		return golox.TerminateAction
+	}
+	if reason == golox.PauseReason && s.entry {
+		reason = "entry"
+	}
+	s.entry = false
+	s.paused = true
+	s.frames = d.Frames()
+	s.references = s.references[:0]
+	s.mu.Unlock()
+
+	s.write(&event{Type: "event", Event: "stopped", Body: map[string]interface{}{
+		"reason":            reason,
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	}})
+
+	return <-s.resume
+}
+
+func (s *Server) resumeWith(action golox.DebugAction) error {
+	s.mu.Lock()
+	if !s.paused {
+		s.mu.Unlock()
+		return errors.New("program is not paused")
+	}
+	s.paused = false
+	s.mu.Unlock()
+
+	s.resume <- action
+	return nil
+}
+
+// terminate stops the program if it is running and waits for it
+func (s *Server) terminate() {
+	s.mu.Lock()
+	running, paused := s.running, s.paused
+	s.paused = false
+	s.terminating = true
+	s.mu.Unlock()
+
+	if !running {
+		return
+	}
+	if paused {
+		s.resume <- golox.TerminateAction
+	} else {
+		// the program is terminated when it pauses at the next statement
+		s.debugger.Pause()
+	}
+	<-s.done
+}
+
+func (s *Server) stackTrace() ([]StackFrame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return nil, errors.New("program is not paused")
+	}
+
+	frames := make([]StackFrame, 0)
+	for k, frame := range s.frames {
+		if frame.Position == nil {
+			continue
+		}
+		frames = append(frames, StackFrame{
+			ID:     k + 1,
+			Name:   frame.Name,
+			Source: Source{Name: filepath.Base(frame.Position.File), Path: frame.Position.File},
+			Line:   frame.Position.Line,
+			Column: frame.Position.Column,
+		})
+	}
+	return frames, nil
+}
+
+func (s *Server) scopes(frameID int) ([]Scope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused || frameID < 1 || frameID > len(s.frames) {
+		return nil, fmt.Errorf("invalid frame id: %d", frameID)
+	}
+
+	scopes := make([]Scope, 0)
+	env := s.frames[frameID-1].Environment
+	for depth := 0; env != nil; depth, env = depth+1, env.Enclosing {
+		name := "Locals"
+		if env.Enclosing == nil {
+			name = "Globals"
+		} else if depth > 0 {
+			name = "Enclosing " + strconv.Itoa(depth)
+		}
+		scopes = append(scopes, Scope{Name: name, VariablesReference: s.reference(env)})
+	}
+	return scopes, nil
+}
+
+func (s *Server) variables(ref int) ([]Variable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused || ref < 1 || ref > len(s.references) {
+		return nil, fmt.Errorf("invalid variables reference: %d", ref)
+	}
+
+	var values map[string]interface{}
+	switch v := s.references[ref-1].(type) {
+	case *golox.Environment:
+		values = v.Values
+	case *golox.GoLoxInstance:
+		values = v.Fields
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variables := make([]Variable, 0)
+	for _, name := range names {
+		variable := Variable{Name: name, Value: golox.Stringfy(values[name])}
+		if instance, ok := values[name].(*golox.GoLoxInstance); ok {
+			variable.VariablesReference = s.reference(instance)
+		}
+		variables = append(variables, variable)
+	}
+	return variables, nil
+}
+
+// reference returns variablesReference of the container. It is valid until the program resumes.
+func (s *Server) reference(container interface{}) int {
+	s.references = append(s.references, container)
+	return len(s.references)
+}
+
+func (s *Server) output(category, text string) {
+	s.write(&event{Type: "event", Event: "output", Body: map[string]string{"category": category, "output": text}})
+}
+
+// read reads a message framed by Content-Length header
+func (s *Server) read() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(line), "content-length:") {
+			length, err = strconv.Atoi(strings.TrimSpace(line[len("content-length:"):]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %v", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	_, err := io.ReadFull(s.reader, body)
+	return body, err
+}
+
+// write sends a response or an event. It is safe to call from the program goroutine.
+func (s *Server) write(msg interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.seq++
+	switch m := msg.(type) {
+	case *response:
+		m.Seq = s.seq
+	case *event:
+		m.Seq = s.seq
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// outputWriter sends output of the program as output events
+type outputWriter struct {
+	server   *Server
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.server.output(w.category, string(p))
+	return len(p), nil
+}
@@ -0,0 +1,219 @@
+package dap_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/golox/dap"
+	"github.com/stretchr/testify/assert"
+)
+
+const source = `fun add(a, b) {
+  var c = a + b;
+  return c;
+}
+var x = 1;
+print add(x, 2);
+print "done";
+`
+
+// message is a response or an event sent by the server
+type message struct {
+	Type       string          `json:"type"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Command    string          `json:"command"`
+	Message    string          `json:"message"`
+	Event      string          `json:"event"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// client is scripted DAP client
+type client struct {
+	t      *testing.T
+	writer io.Writer
+	reader *bufio.Reader
+	seq    int
+	events []*message
+}
+
+func (c *client) receive() *message {
+	length := 0
+	for {
+		line, err := c.reader.ReadString('\n')
+		assert.NoError(c.t, err)
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		length, _ = strconv.Atoi(strings.TrimPrefix(line, "Content-Length: "))
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(c.reader, body)
+	assert.NoError(c.t, err)
+
+	var msg message
+	assert.NoError(c.t, json.Unmarshal(body, &msg))
+	return &msg
+}
+
+// request sends a request and returns the response. Events received meanwhile are queued.
+func (c *client) request(command string, arguments interface{}) *message {
+	c.seq++
+	body, err := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+	assert.NoError(c.t, err)
+	fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+
+	for {
+		msg := c.receive()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+		assert.Equal(c.t, c.seq, msg.RequestSeq)
+		assert.Equal(c.t, command, msg.Command)
+		return msg
+	}
+}
+
+// event returns body of the next event
+func (c *client) event(name string) string {
+	var msg *message
+	if len(c.events) > 0 {
+		msg, c.events = c.events[0], c.events[1:]
+	} else {
+		msg = c.receive()
+	}
+	assert.Equal(c.t, name, msg.Event)
+	return string(msg.Body)
+}
+
+func TestServer(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "add.lox")
+	assert.NoError(t, ioutil.WriteFile(program, []byte(source), 0644))
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server := dap.NewServer(serverReader, serverWriter)
+	done := make(chan error)
+	go func() {
+		done <- server.Serve()
+		serverWriter.Close()
+	}()
+
+	c := &client{t: t, writer: clientWriter, reader: bufio.NewReader(clientReader)}
+
+	res := c.request("initialize", map[string]string{"adapterID": "golox"})
+	assert.True(t, res.Success)
+	assert.Contains(t, string(res.Body), `"supportsConfigurationDoneRequest":true`)
+	c.event("initialized")
+
+	assert.True(t, c.request("launch", map[string]interface{}{"program": program, "stopOnEntry": true}).Success)
+	res = c.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]string{"path": program},
+		"breakpoints": []map[string]int{{"line": 2}},
+	})
+	assert.JSONEq(t, `{"breakpoints":[{"verified":true,"line":2}]}`, string(res.Body))
+	assert.True(t, c.request("configurationDone", nil).Success)
+	assert.JSONEq(t, `{"reason":"entry","threadId":1,"allThreadsStopped":true}`, c.event("stopped"))
+
+	t.Run("threads", func(t *testing.T) {
+		res := c.request("threads", nil)
+		assert.JSONEq(t, `{"threads":[{"id":1,"name":"main"}]}`, string(res.Body))
+	})
+
+	t.Run("continue to breakpoint", func(t *testing.T) {
+		assert.True(t, c.request("continue", map[string]int{"threadId": 1}).Success)
+		assert.JSONEq(t, `{"reason":"breakpoint","threadId":1,"allThreadsStopped":true}`, c.event("stopped"))
+
+		res := c.request("stackTrace", map[string]int{"threadId": 1})
+		assert.JSONEq(t, `{"stackFrames":[
+			{"id":1,"name":"add","source":{"name":"add.lox","path":"`+program+`"},"line":2,"column":3},
+			{"id":2,"name":"<script>","source":{"name":"add.lox","path":"`+program+`"},"line":6,"column":1}
+		],"totalFrames":2}`, string(res.Body))
+	})
+
+	t.Run("variables", func(t *testing.T) {
+		res := c.request("scopes", map[string]int{"frameId": 1})
+		var scopes struct {
+			Scopes []struct {
+				Name               string
+				VariablesReference int
+			}
+		}
+		assert.NoError(t, json.Unmarshal(res.Body, &scopes))
+		assert.Equal(t, "Locals", scopes.Scopes[0].Name)
+		assert.Equal(t, "Globals", scopes.Scopes[len(scopes.Scopes)-1].Name)
+
+		res = c.request("variables", map[string]int{"variablesReference": scopes.Scopes[0].VariablesReference})
+		assert.JSONEq(t, `{"variables":[
+			{"name":"a","value":"1","variablesReference":0},
+			{"name":"b","value":"2","variablesReference":0}
+		]}`, string(res.Body))
+	})
+
+	t.Run("step", func(t *testing.T) {
+		assert.True(t, c.request("next", map[string]int{"threadId": 1}).Success)
+		assert.JSONEq(t, `{"reason":"step","threadId":1,"allThreadsStopped":true}`, c.event("stopped"))
+
+		assert.True(t, c.request("stepOut", map[string]int{"threadId": 1}).Success)
+		assert.JSONEq(t, `{"category":"stdout","output":"3\n"}`, c.event("output"))
+		c.event("stopped")
+		res := c.request("stackTrace", map[string]int{"threadId": 1})
+		assert.Contains(t, string(res.Body), `"line":7`)
+	})
+
+	t.Run("not paused", func(t *testing.T) {
+		assert.True(t, c.request("continue", map[string]int{"threadId": 1}).Success)
+		assert.JSONEq(t, `{"category":"stdout","output":"done\n"}`, c.event("output"))
+		assert.JSONEq(t, `{"exitCode":0}`, c.event("exited"))
+		c.event("terminated")
+
+		res := c.request("stackTrace", map[string]int{"threadId": 1})
+		assert.False(t, res.Success)
+		assert.Equal(t, "program is not paused", res.Message)
+	})
+
+	t.Run("configurationDone after exit", func(t *testing.T) {
+		res := c.request("configurationDone", nil)
+		assert.False(t, res.Success)
+		assert.Equal(t, "program has already started", res.Message)
+	})
+
+	assert.True(t, c.request("disconnect", nil).Success)
+	assert.NoError(t, <-done)
+}
+
+func TestServer_Disconnect(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "loop.lox")
+	assert.NoError(t, ioutil.WriteFile(program, []byte("while (true) {}\n"), 0644))
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server := dap.NewServer(serverReader, serverWriter)
+	done := make(chan error)
+	go func() {
+		done <- server.Serve()
+		serverWriter.Close()
+	}()
+
+	c := &client{t: t, writer: clientWriter, reader: bufio.NewReader(clientReader)}
+	c.request("initialize", map[string]string{"adapterID": "golox"})
+	c.event("initialized")
+	c.request("launch", map[string]interface{}{"program": program})
+	c.request("configurationDone", nil)
+
+	res := c.request("configurationDone", nil)
+	assert.False(t, res.Success)
+	assert.Equal(t, "program has already started", res.Message)
+
+	assert.True(t, c.request("disconnect", map[string]bool{"terminateDebuggee": true}).Success)
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,268 @@
+package golox
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrTerminated is returned when the debugger terminates the program
+var ErrTerminated = errors.New("terminated by debugger")
+
+// DebugAction tells the debugger how to resume execution after pause
+type DebugAction int
+
+const (
+	// ContinueAction runs until the next breakpoint
+	ContinueAction DebugAction = iota
+	// StepIntoAction pauses at the next statement
+	StepIntoAction
+	// StepOverAction pauses at the next statement of the current or outer function
+	StepOverAction
+	// StepOutAction pauses after the current function returns
+	StepOutAction
+	// TerminateAction stops the program
+	TerminateAction
+)
+
+// reasons why the program is paused
+const (
+	BreakpointReason = "breakpoint"
+	StepReason       = "step"
+	PauseReason      = "pause"
+)
+
+// Breakpoint is a location where the debugger pauses the program
+type Breakpoint struct {
+	File string
+	Line int
+}
+
+// Frame is an entry of call stack
+type Frame struct {
+	Name        string
+	Position    *Position
+	Environment *Environment
+	stmt        Stmt
+}
+
+// Debugger pauses the program at breakpoints and steps through statements.
+// Register it by Runtime.AddTracer. Breakpoints and Pause may be used from another goroutine.
+type Debugger struct {
+	mu          sync.Mutex
+	breakpoints map[string]map[int]bool
+	paused      bool
+	frames      []*Frame
+	action      DebugAction
+	depth       int
+	terminated  bool
+	// OnPause is called when the program is paused.
+	// The program is resumed with returned action.
+	OnPause func(d *Debugger, reason string) DebugAction
+}
+
+// NewDebugger is constructor of Debugger
+func NewDebugger(onPause func(d *Debugger, reason string) DebugAction) *Debugger {
+	return &Debugger{
+		breakpoints: make(map[string]map[int]bool),
+		frames:      make([]*Frame, 0),
+		action:      ContinueAction,
+		paused:      false,
+		depth:       0,
+		terminated:  false,
+		OnPause:     onPause,
+	}
+}
+
+// Pause makes the debugger pause at the next statement
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = true
+}
+
+// SetBreakpoint sets a breakpoint at line of file.
+// A relative file matches the scripts whose path ends with it.
+func (d *Debugger) SetBreakpoint(file string, line int) {
+	file = cleanPath(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.breakpoints[file]; !ok {
+		d.breakpoints[file] = make(map[int]bool)
+	}
+	d.breakpoints[file][line] = true
+}
+
+// ClearBreakpoint removes the breakpoint at line of file
+func (d *Debugger) ClearBreakpoint(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.breakpoints[cleanPath(file)], line)
+}
+
+// ClearBreakpoints removes all breakpoints in file
+func (d *Debugger) ClearBreakpoints(file string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.breakpoints, cleanPath(file))
+}
+
+// Breakpoints returns breakpoints sorted by file and line
+func (d *Debugger) Breakpoints() []*Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	breakpoints := make([]*Breakpoint, 0)
+	for file, lines := range d.breakpoints {
+		for line := range lines {
+			breakpoints = append(breakpoints, &Breakpoint{File: file, Line: line})
+		}
+	}
+	sort.Slice(breakpoints, func(i, j int) bool {
+		if breakpoints[i].File != breakpoints[j].File {
+			return breakpoints[i].File < breakpoints[j].File
+		}
+		return breakpoints[i].Line < breakpoints[j].Line
+	})
+	return breakpoints
+}
+
+// HasBreakpoint reports whether a breakpoint is set at line of file
+func (d *Debugger) HasBreakpoint(file string, line int) bool {
+	file = cleanPath(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for bpFile, lines := range d.breakpoints {
+		if !lines[line] {
+			continue
+		}
+		if bpFile == file || strings.HasSuffix(file, string(filepath.Separator)+bpFile) {
+			return true
+		}
+	}
+	return false
+}
+
+// Frames returns call stack. The innermost frame comes first.
+func (d *Debugger) Frames() []*Frame {
+	frames := make([]*Frame, 0, len(d.frames))
+	for k := len(d.frames) - 1; k >= 0; k-- {
+		frames = append(frames, d.frames[k])
+	}
+	return frames
+}
+
+// Lookup returns value of the variable visible from the frame
+func (d *Debugger) Lookup(frame *Frame, name string) (interface{}, bool) {
+	for env := frame.Environment; env != nil; env = env.Enclosing {
+		if v, ok := env.Values[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Statement pauses the program if needed
+func (d *Debugger) Statement(i *Interpreter, stmt Stmt) error {
+	if d.terminated {
+		return ErrTerminated
+	}
+	pos, ok := i.Runtime.Positions[stmt]
+	if !ok {
+		return nil
+	}
+
+	if len(d.frames) == 0 {
+		d.frames = append(d.frames, &Frame{Name: "<script>"})
+	}
+	frame := d.frames[len(d.frames)-1]
+	nested := frame.stmt != nil && nestedOnSameLine(frame.stmt, frame.Position, pos)
+	frame.Position = pos
+	frame.Environment = i.Runtime.Environment
+	frame.stmt = stmt
+
+	var reason string
+	if _, ok := i.Runtime.ForLoops[stmt]; nested || !ok && stmt.IsType(&Block{}) {
+		// only pause request stops here, e.g. in an empty loop
+		if d.takePause() {
+			reason = PauseReason
+		}
+	} else {
+		reason = d.reason(pos)
+	}
+	if reason == "" {
+		return nil
+	}
+
+	d.action = d.OnPause(d, reason)
+	d.depth = len(d.frames)
+	if d.action == TerminateAction {
+		d.terminated = true
+		return ErrTerminated
+	}
+	return nil
+}
+
+// Call pushes a frame of the function
+func (d *Debugger) Call(i *Interpreter, function *GoLoxFunction) {
+	d.frames = append(d.frames, &Frame{Name: function.Name(), Environment: i.Runtime.Environment})
+}
+
+// Return pops the frame of the function
+func (d *Debugger) Return(i *Interpreter, function *GoLoxFunction) {
+	d.frames = d.frames[:len(d.frames)-1]
+}
+
+// reason returns why the program should be paused at pos. It is empty if not paused.
+func (d *Debugger) reason(pos *Position) string {
+	if d.HasBreakpoint(pos.File, pos.Line) {
+		d.takePause()
+		return BreakpointReason
+	}
+	if d.takePause() {
+		return PauseReason
+	}
+
+	switch d.action {
+	case StepIntoAction:
+		return StepReason
+	case StepOverAction:
+		if len(d.frames) <= d.depth {
+			return StepReason
+		}
+	case StepOutAction:
+		if len(d.frames) < d.depth {
+			return StepReason
+		}
+	}
+	return ""
+}
+
+// nestedOnSameLine reports whether the statement at pos is nested in previous statement on the same line.
+// Such statements are a part of the same step.
+func nestedOnSameLine(prev Stmt, prevPos, pos *Position) bool {
+	switch prev.(type) {
+	case *Block, *If, *While:
+		return prevPos.File == pos.File && prevPos.Line == pos.Line
+	}
+	return false
+}
+
+func cleanPath(path string) string {
+	if path == "" {
+		return path
+	}
+	return filepath.Clean(path)
+}
+
+// takePause reports whether pause is requested and clears the request
+func (d *Debugger) takePause() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	paused := d.paused
+	d.paused = false
+	return paused
+}
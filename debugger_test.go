@@ -0,0 +1,163 @@
+package golox_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+const debuggee = `fun add(a, b) {
+  var c = a + b;
+  return c;
+}
+var x = 1;
+if (x > 0) print x;
+print add(x, 2);
+for (var i = 0; i < 2; i = i + 1) {
+  print i;
+}
+print "done";
+`
+
+// pauses runs debuggee with the actions and returns where it paused
+func pauses(breakpoints []int, stopOnEntry bool, actions ...golox.DebugAction) ([]string, string) {
+	r := golox.NewRuntime()
+	stdout := &bytes.Buffer{}
+	r.Stdout = stdout
+	r.File = "/tmp/debuggee.lox"
+
+	paused := make([]string, 0)
+	debugger := golox.NewDebugger(func(d *golox.Debugger, reason string) golox.DebugAction {
+		frames := make([]string, 0)
+		for _, frame := range d.Frames() {
+			frames = append(frames, fmt.Sprintf("%s:%d", frame.Name, frame.Position.Line))
+		}
+		paused = append(paused, reason+" "+strings.Join(frames, " "))
+
+		if len(actions) == 0 {
+			return golox.ContinueAction
+		}
+		action := actions[0]
+		actions = actions[1:]
+		return action
+	})
+	for _, line := range breakpoints {
+		debugger.SetBreakpoint("debuggee.lox", line)
+	}
+	if stopOnEntry {
+		debugger.Pause()
+	}
+	r.AddTracer(debugger)
+	r.Run(bytes.NewBufferString(debuggee))
+
+	return paused, stdout.String()
+}
+
+func TestDebugger(t *testing.T) {
+	var tests = []struct {
+		name        string
+		expected    []string
+		breakpoints []int
+		stopOnEntry bool
+		actions     []golox.DebugAction
+	}{
+		{
+			name:        "breakpoint in function",
+			expected:    []string{"breakpoint add:2 <script>:7"},
+			breakpoints: []int{2},
+		},
+		{
+			name:        "breakpoint in loop",
+			expected:    []string{"breakpoint <script>:9", "breakpoint <script>:9"},
+			breakpoints: []int{9},
+		},
+		{
+			name:        "statements on the same line are one step",
+			expected:    []string{"breakpoint <script>:6", "step <script>:7"},
+			breakpoints: []int{6},
+			actions:     []golox.DebugAction{golox.StepIntoAction, golox.ContinueAction},
+		},
+		{
+			name:        "step into",
+			expected:    []string{"breakpoint <script>:7", "step add:2 <script>:7", "step add:3 <script>:7", "step <script>:8"},
+			breakpoints: []int{7},
+			actions:     []golox.DebugAction{golox.StepIntoAction, golox.StepIntoAction, golox.StepIntoAction},
+		},
+		{
+			name:        "step over",
+			expected:    []string{"breakpoint <script>:7", "step <script>:8", "step <script>:9"},
+			breakpoints: []int{7},
+			actions:     []golox.DebugAction{golox.StepOverAction, golox.StepOverAction},
+		},
+		{
+			name:        "step out",
+			expected:    []string{"breakpoint add:2 <script>:7", "step <script>:8"},
+			breakpoints: []int{2},
+			actions:     []golox.DebugAction{golox.StepOutAction},
+		},
+		{
+			name:        "stop on entry",
+			expected:    []string{"pause <script>:1", "step <script>:5"},
+			stopOnEntry: true,
+			actions:     []golox.DebugAction{golox.StepOverAction},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, _ := pauses(tt.breakpoints, tt.stopOnEntry, tt.actions...)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestDebugger_Terminate(t *testing.T) {
+	paused, stdout := pauses([]int{7}, false, golox.TerminateAction)
+	assert.Equal(t, []string{"breakpoint <script>:7"}, paused)
+	assert.Equal(t, "1\n", stdout)
+}
+
+func TestDebugger_Inspect(t *testing.T) {
+	r := golox.NewRuntime()
+	r.Stdout = &bytes.Buffer{}
+
+	var values []interface{}
+	var names []string
+	debugger := golox.NewDebugger(func(d *golox.Debugger, reason string) golox.DebugAction {
+		frame := d.Frames()[0]
+		for _, name := range []string{"a", "c", "x", "undefined"} {
+			v, ok := d.Lookup(frame, name)
+			values = append(values, v)
+			if !ok {
+				names = append(names, name)
+			}
+		}
+		return golox.ContinueAction
+	})
+	debugger.SetBreakpoint("", 3)
+	r.AddTracer(debugger)
+	r.Run(bytes.NewBufferString(debuggee))
+
+	assert.Equal(t, []interface{}{1.0, 3.0, 1.0, nil}, values)
+	assert.Equal(t, []string{"undefined"}, names)
+}
+
+func TestDebugger_Breakpoints(t *testing.T) {
+	debugger := golox.NewDebugger(nil)
+	debugger.SetBreakpoint("b.lox", 3)
+	debugger.SetBreakpoint("a.lox", 10)
+	debugger.SetBreakpoint("a.lox", 2)
+	debugger.SetBreakpoint("./b.lox", 5)
+	debugger.ClearBreakpoint("b.lox", 3)
+
+	assert.Equal(t, []*golox.Breakpoint{{File: "a.lox", Line: 2}, {File: "a.lox", Line: 10}, {File: "b.lox", Line: 5}}, debugger.Breakpoints())
+	assert.True(t, debugger.HasBreakpoint("/src/a.lox", 2))
+	assert.False(t, debugger.HasBreakpoint("/src/ba.lox", 2))
+
+	debugger.ClearBreakpoints("a.lox")
+	assert.Equal(t, []*golox.Breakpoint{{File: "b.lox", Line: 5}}, debugger.Breakpoints())
+}
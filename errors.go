@@ -15,6 +15,11 @@ func (e *CustomError) Error() string {
 	return e.typ + ": " + e.message
 }
 
+// Message returns message of the error without its type
+func (e *CustomError) Message() string {
+	return e.message
+}
+
 func (e *CustomError) New(token *Token, message string) error {
 	return &CustomError{typ: e.typ, Token: token, message: message}
 }
@@ -22,3 +27,22 @@ func (e *CustomError) New(token *Token, message string) error {
 func NewCustomError(typ string) *CustomError {
 	return &CustomError{typ: typ}
 }
+
+// Diagnostic is an error reported by scanner, parser or resolver.
+// Column is 0 if the error isn't associated with a token.
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Lexeme  string
+	Message string
+}
+
+// NewDiagnostic is constructor of Diagnostic
+func NewDiagnostic(line, column int, lexeme, message string) *Diagnostic {
+	return &Diagnostic{
+		Line:    line,
+		Column:  column,
+		Lexeme:  lexeme,
+		Message: message,
+	}
+}
@@ -9,11 +9,20 @@ type VisitorExpr interface {
 	visitAssignExpr(*Assign) (interface{}, error)
 	visitBinaryExpr(*Binary) (interface{}, error)
 	visitCallExpr(*Call) (interface{}, error)
+	visitCompoundAssignExpr(*CompoundAssign) (interface{}, error)
+	visitConditionalExpr(*Conditional) (interface{}, error)
 	visitGetExpr(*Get) (interface{}, error)
 	visitGroupingExpr(*Grouping) (interface{}, error)
+	visitIndexExpr(*Index) (interface{}, error)
+	visitInterpolationExpr(*Interpolation) (interface{}, error)
+	visitLambdaExpr(*Lambda) (interface{}, error)
+	visitListExpr(*List) (interface{}, error)
 	visitLiteralExpr(*Literal) (interface{}, error)
 	visitLogicalExpr(*Logical) (interface{}, error)
+	visitMatchExpr(*Match) (interface{}, error)
+	visitOptionalChainExpr(*OptionalChain) (interface{}, error)
 	visitSetExpr(*Set) (interface{}, error)
+	visitSetIndexExpr(*SetIndex) (interface{}, error)
 	visitSuperExpr(*Super) (interface{}, error)
 	visitThisExpr(*This) (interface{}, error)
 	visitUnaryExpr(*Unary) (interface{}, error)
@@ -85,13 +94,59 @@ func (rec *Call) IsType(v interface{}) bool {
 	return false
 }
 
+type CompoundAssign struct {
+	Target   Expr
+	Operator *Token
+	Value    Expr
+	Postfix  bool
+}
+
+func NewCompoundAssign(target Expr, operator *Token, value Expr, postfix bool) Expr {
+	return &CompoundAssign{target, operator, value, postfix}
+}
+
+func (c *CompoundAssign) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitCompoundAssignExpr(c)
+}
+
+func (rec *CompoundAssign) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *CompoundAssign:
+		return true
+	}
+	return false
+}
+
+type Conditional struct {
+	Condition  Expr
+	ThenBranch Expr
+	ElseBranch Expr
+}
+
+func NewConditional(condition Expr, thenBranch Expr, elseBranch Expr) Expr {
+	return &Conditional{condition, thenBranch, elseBranch}
+}
+
+func (c *Conditional) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitConditionalExpr(c)
+}
+
+func (rec *Conditional) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Conditional:
+		return true
+	}
+	return false
+}
+
 type Get struct {
-	Object Expr
-	Name   *Token
+	Object   Expr
+	Name     *Token
+	Optional bool
 }
 
-func NewGet(object Expr, name *Token) Expr {
-	return &Get{object, name}
+func NewGet(object Expr, name *Token, optional bool) Expr {
+	return &Get{object, name, optional}
 }
 
 func (g *Get) Accept(visitor VisitorExpr) (interface{}, error) {
@@ -126,6 +181,90 @@ func (rec *Grouping) IsType(v interface{}) bool {
 	return false
 }
 
+type Index struct {
+	Object  Expr
+	Bracket *Token
+	Index   Expr
+}
+
+func NewIndex(object Expr, bracket *Token, index Expr) Expr {
+	return &Index{object, bracket, index}
+}
+
+func (i *Index) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitIndexExpr(i)
+}
+
+func (rec *Index) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Index:
+		return true
+	}
+	return false
+}
+
+type Interpolation struct {
+	Parts []Expr
+}
+
+func NewInterpolation(parts []Expr) Expr {
+	return &Interpolation{parts}
+}
+
+func (i *Interpolation) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitInterpolationExpr(i)
+}
+
+func (rec *Interpolation) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Interpolation:
+		return true
+	}
+	return false
+}
+
+type Lambda struct {
+	Keyword  *Token
+	Function *Function
+}
+
+func NewLambda(keyword *Token, function *Function) Expr {
+	return &Lambda{keyword, function}
+}
+
+func (l *Lambda) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitLambdaExpr(l)
+}
+
+func (rec *Lambda) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Lambda:
+		return true
+	}
+	return false
+}
+
+type List struct {
+	Bracket  *Token
+	Elements []Expr
+}
+
+func NewList(bracket *Token, elements []Expr) Expr {
+	return &List{bracket, elements}
+}
+
+func (l *List) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitListExpr(l)
+}
+
+func (rec *List) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *List:
+		return true
+	}
+	return false
+}
+
 type Literal struct {
 	Value interface{}
 }
@@ -168,6 +307,48 @@ func (rec *Logical) IsType(v interface{}) bool {
 	return false
 }
 
+type Match struct {
+	Keyword *Token
+	Value   Expr
+	Arms    []*MatchArm
+}
+
+func NewMatch(keyword *Token, value Expr, arms []*MatchArm) Expr {
+	return &Match{keyword, value, arms}
+}
+
+func (m *Match) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitMatchExpr(m)
+}
+
+func (rec *Match) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Match:
+		return true
+	}
+	return false
+}
+
+type OptionalChain struct {
+	Expression Expr
+}
+
+func NewOptionalChain(expression Expr) Expr {
+	return &OptionalChain{expression}
+}
+
+func (o *OptionalChain) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitOptionalChainExpr(o)
+}
+
+func (rec *OptionalChain) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *OptionalChain:
+		return true
+	}
+	return false
+}
+
 type Set struct {
 	Object Expr
 	Name   *Token
@@ -190,6 +371,29 @@ func (rec *Set) IsType(v interface{}) bool {
 	return false
 }
 
+type SetIndex struct {
+	Object  Expr
+	Bracket *Token
+	Index   Expr
+	Value   Expr
+}
+
+func NewSetIndex(object Expr, bracket *Token, index Expr, value Expr) Expr {
+	return &SetIndex{object, bracket, index, value}
+}
+
+func (s *SetIndex) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.visitSetIndexExpr(s)
+}
+
+func (rec *SetIndex) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *SetIndex:
+		return true
+	}
+	return false
+}
+
 type Super struct {
 	Keyword *Token
 	Method  *Token
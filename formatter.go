@@ -0,0 +1,521 @@
+package golox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goropikari/golox/native_function"
+)
+
+const indentWidth = 4
+
+// Formatter is struct of pretty printer which reconstructs source code from ast
+type Formatter struct {
+	runtime    *Runtime
+	comments   TokenList
+	methods    map[*Function]bool
+	buf        *bytes.Buffer
+	indent     int
+	lastLine   int
+	blockStart bool
+}
+
+// NewFormatter is constructor of Formatter.
+// comments are the ones collected by Scanner.ScanTokensWithComments.
+func NewFormatter(runtime *Runtime, comments TokenList) *Formatter {
+	return &Formatter{
+		runtime:    runtime,
+		comments:   comments,
+		methods:    make(map[*Function]bool),
+		buf:        &bytes.Buffer{},
+		indent:     0,
+		lastLine:   0,
+		blockStart: true,
+	}
+}
+
+// Format formats given source code in canonical style
+func Format(source []byte) ([]byte, error) {
+	stderr := &bytes.Buffer{}
+	r := NewRuntime()
+	r.Stderr = stderr
+
+	scanner := NewScanner(r, bytes.NewBuffer(source))
+	tokens, comments := scanner.ScanTokensWithComments()
+	statements, _ := NewParser(r, tokens).Parse()
+	if r.HadError {
+		return nil, errors.New(strings.TrimSpace(stderr.String()))
+	}
+
+	return []byte(NewFormatter(r, comments).Format(statements)), nil
+}
+
+// Format returns source code of given statements
+func (f *Formatter) Format(stmts []Stmt) string {
+	f.buf.Reset()
+	f.writeStmts(stmts)
+	f.flushComments(-1)
+
+	return f.buf.String()
+}
+
+func (f *Formatter) visitBlockStmt(stmt *Block) (interface{}, error) {
+	pos := f.position(stmt)
+	f.writeBlock(stmt.Statements, pos.Line, pos.EndLine)
+	return nil, nil
+}
+
+func (f *Formatter) visitCaseStmt(stmt *Case) (interface{}, error) {
+	pos := f.position(stmt)
+	if stmt.Values == nil {
+		f.write("default:")
+	} else {
+		values := make([]string, 0, len(stmt.Values))
+		for _, value := range stmt.Values {
+			values = append(values, f.expr(value))
+		}
+		f.write("case " + strings.Join(values, ", ") + ":")
+	}
+	if len(stmt.Body) == 0 {
+		return nil, nil
+	}
+
+	f.writeTrailingComments(pos.Line)
+	f.newline()
+	f.indent++
+	f.lastLine = pos.Line
+	f.blockStart = true
+	f.writeStmts(stmt.Body)
+	f.indent--
+	// writeStmts of the switch body ends the case with a newline
+	f.buf.Truncate(f.buf.Len() - 1)
+	return nil, nil
+}
+
+func (f *Formatter) visitClassStmt(stmt *Class) (interface{}, error) {
+	pos := f.position(stmt)
+	f.write("class " + stmt.Name.Lexeme + " ")
+	if stmt.Superclass != nil {
+		f.write("< " + stmt.Superclass.Name.Lexeme + " ")
+	}
+
+	methods := make([]Stmt, 0)
+	for _, method := range stmt.Methods {
+		f.methods[method] = true
+		methods = append(methods, method)
+	}
+	f.writeBlock(methods, pos.Line, pos.EndLine)
+	return nil, nil
+}
+
+func (f *Formatter) visitExpressionStmt(stmt *Expression) (interface{}, error) {
+	f.write(f.expr(stmt.Expression) + ";")
+	return nil, nil
+}
+
+func (f *Formatter) visitFunctionStmt(stmt *Function) (interface{}, error) {
+	pos := f.position(stmt)
+	if f.methods[stmt] {
+		f.write(stmt.Name.Lexeme)
+	} else {
+		f.write("fun " + stmt.Name.Lexeme)
+	}
+	f.write("(" + joinTokens(stmt.Params) + ") ")
+	f.writeBlock(stmt.Body, pos.Line, pos.EndLine)
+	return nil, nil
+}
+
+func (f *Formatter) visitIfStmt(stmt *If) (interface{}, error) {
+	f.write("if (" + f.expr(stmt.Condition) + ")")
+	f.writeBody(stmt.ThenBranch)
+	if stmt.ElseBranch == nil {
+		return nil, nil
+	}
+
+	if stmt.ThenBranch.IsType(&Block{}) {
+		f.write(" else")
+	} else {
+		f.newline()
+		f.writeIndent()
+		f.write("else")
+	}
+	if stmt.ElseBranch.IsType(&If{}) {
+		f.write(" ")
+		f.writeStmt(stmt.ElseBranch)
+	} else {
+		f.writeBody(stmt.ElseBranch)
+	}
+	return nil, nil
+}
+
+func (f *Formatter) visitIncludeStmt(stmt *Include) (interface{}, error) {
+	f.write("include " + stmt.Path.Lexeme + ";")
+	return nil, nil
+}
+
+func (f *Formatter) visitPrintStmt(stmt *Print) (interface{}, error) {
+	f.write("print " + f.expr(stmt.Expression) + ";")
+	return nil, nil
+}
+
+func (f *Formatter) visitReturnStmt(stmt *Return) (interface{}, error) {
+	if stmt.Value == nil {
+		f.write("return;")
+	} else {
+		f.write("return " + f.expr(stmt.Value) + ";")
+	}
+	return nil, nil
+}
+
+func (f *Formatter) visitSwitchStmt(stmt *Switch) (interface{}, error) {
+	pos := f.position(stmt)
+	f.write("switch (" + f.expr(stmt.Value) + ") ")
+
+	cases := make([]Stmt, 0, len(stmt.Cases))
+	for _, c := range stmt.Cases {
+		cases = append(cases, c)
+	}
+	f.writeBlock(cases, pos.Line, pos.EndLine)
+	return nil, nil
+}
+
+func (f *Formatter) visitVarStmt(stmt *Var) (interface{}, error) {
+	if stmt.Initializer == nil {
+		f.write("var " + stmt.Name.Lexeme + ";")
+	} else {
+		f.write("var " + stmt.Name.Lexeme + " = " + f.expr(stmt.Initializer) + ";")
+	}
+	return nil, nil
+}
+
+func (f *Formatter) visitWhileStmt(stmt *While) (interface{}, error) {
+	f.write("while (" + f.expr(stmt.Condition) + ")")
+	f.writeBody(stmt.Body)
+	return nil, nil
+}
+
+func (f *Formatter) visitAssignExpr(expr *Assign) (interface{}, error) {
+	return expr.Name.Lexeme + " = " + f.expr(expr.Value), nil
+}
+
+func (f *Formatter) visitBinaryExpr(expr *Binary) (interface{}, error) {
+	return f.expr(expr.Left) + " " + expr.Operator.Lexeme + " " + f.expr(expr.Right), nil
+}
+
+func (f *Formatter) visitCallExpr(expr *Call) (interface{}, error) {
+	args := make([]string, 0)
+	for _, argument := range expr.Arguments {
+		args = append(args, f.expr(argument))
+	}
+	return f.expr(expr.Callee) + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+func (f *Formatter) visitCompoundAssignExpr(expr *CompoundAssign) (interface{}, error) {
+	if expr.Postfix {
+		return f.expr(expr.Target) + expr.Operator.Lexeme, nil
+	}
+	return f.expr(expr.Target) + " " + expr.Operator.Lexeme + " " + f.expr(expr.Value), nil
+}
+
+func (f *Formatter) visitConditionalExpr(expr *Conditional) (interface{}, error) {
+	return f.expr(expr.Condition) + " ? " + f.expr(expr.ThenBranch) + " : " + f.expr(expr.ElseBranch), nil
+}
+
+func (f *Formatter) visitGetExpr(expr *Get) (interface{}, error) {
+	if expr.Optional {
+		return f.expr(expr.Object) + "?." + expr.Name.Lexeme, nil
+	}
+	return f.expr(expr.Object) + "." + expr.Name.Lexeme, nil
+}
+
+func (f *Formatter) visitGroupingExpr(expr *Grouping) (interface{}, error) {
+	return "(" + f.expr(expr.Expression) + ")", nil
+}
+
+func (f *Formatter) visitIndexExpr(expr *Index) (interface{}, error) {
+	return f.expr(expr.Object) + "[" + f.expr(expr.Index) + "]", nil
+}
+
+func (f *Formatter) visitInterpolationExpr(expr *Interpolation) (interface{}, error) {
+	var buf strings.Builder
+	buf.WriteString("\"")
+	// parts of the string and embedded expressions appear alternately
+	for k, part := range expr.Parts {
+		if k%2 == 0 {
+			buf.WriteString(escape(part.(*Literal).Value.(string)))
+			continue
+		}
+		buf.WriteString("${" + f.expr(part) + "}")
+	}
+	buf.WriteString("\"")
+	return buf.String(), nil
+}
+
+func (f *Formatter) visitLiteralExpr(expr *Literal) (interface{}, error) {
+	token, written := f.runtime.LiteralTokens[expr]
+	switch v := expr.Value.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		if written && isRawString(token) {
+			return token.Lexeme, nil
+		}
+		return quote(v), nil
+	case float64:
+		// keeps the notation such as 0xFF and 1_000
+		if written {
+			return token.Lexeme, nil
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return fmt.Sprint(expr.Value), nil
+}
+
+func (f *Formatter) visitListExpr(expr *List) (interface{}, error) {
+	elements := make([]string, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		elements = append(elements, f.expr(element))
+	}
+	return "[" + strings.Join(elements, ", ") + "]", nil
+}
+
+func (f *Formatter) visitLogicalExpr(expr *Logical) (interface{}, error) {
+	return f.expr(expr.Left) + " " + expr.Operator.Lexeme + " " + f.expr(expr.Right), nil
+}
+
+func (f *Formatter) visitLambdaExpr(expr *Lambda) (interface{}, error) {
+	function := expr.Function
+	params := joinTokens(function.Params)
+	if expr.Keyword.Type != ArrowTT {
+		return "fun (" + params + ") " + f.block(function), nil
+	}
+
+	if len(function.Params) != 1 {
+		params = "(" + params + ")"
+	}
+	// the body of a => b is return statement whose keyword is the arrow
+	if len(function.Body) == 1 {
+		if ret, ok := function.Body[0].(*Return); ok && ret.Keyword == expr.Keyword {
+			return params + " => " + f.expr(ret.Value), nil
+		}
+	}
+	return params + " => " + f.block(function), nil
+}
+
+// block returns the body of function formatted at the current indent
+func (f *Formatter) block(function *Function) string {
+	buf := f.buf
+	f.buf = &bytes.Buffer{}
+	pos := f.position(function)
+	f.writeBlock(function.Body, pos.Line, pos.EndLine)
+	s := f.buf.String()
+	f.buf = buf
+	return s
+}
+
+// visitMatchExpr writes each arm on its own line
+func (f *Formatter) visitMatchExpr(expr *Match) (interface{}, error) {
+	var buf strings.Builder
+	buf.WriteString("match (" + f.expr(expr.Value) + ") {\n")
+	f.indent++
+	for _, arm := range expr.Arms {
+		buf.WriteString(strings.Repeat(" ", f.indent*indentWidth))
+		buf.WriteString(arm.Pattern.String() + " => " + f.expr(arm.Body) + ",\n")
+	}
+	f.indent--
+	buf.WriteString(strings.Repeat(" ", f.indent*indentWidth) + "}")
+	return buf.String(), nil
+}
+
+func (f *Formatter) visitOptionalChainExpr(expr *OptionalChain) (interface{}, error) {
+	return f.expr(expr.Expression), nil
+}
+
+func (f *Formatter) visitSetExpr(expr *Set) (interface{}, error) {
+	return f.expr(expr.Object) + "." + expr.Name.Lexeme + " = " + f.expr(expr.Value), nil
+}
+
+func (f *Formatter) visitSetIndexExpr(expr *SetIndex) (interface{}, error) {
+	return f.expr(expr.Object) + "[" + f.expr(expr.Index) + "] = " + f.expr(expr.Value), nil
+}
+
+func (f *Formatter) visitSuperExpr(expr *Super) (interface{}, error) {
+	return "super." + expr.Method.Lexeme, nil
+}
+
+func (f *Formatter) visitThisExpr(expr *This) (interface{}, error) {
+	return "this", nil
+}
+
+func (f *Formatter) visitUnaryExpr(expr *Unary) (interface{}, error) {
+	return expr.Operator.Lexeme + f.expr(expr.Right), nil
+}
+
+func (f *Formatter) visitVariableExpr(expr *Variable) (interface{}, error) {
+	return expr.Name.Lexeme, nil
+}
+
+func (f *Formatter) expr(expr Expr) string {
+	s, _ := expr.Accept(f)
+	return s.(string)
+}
+
+func (f *Formatter) writeStmts(stmts []Stmt) {
+	for _, stmt := range stmts {
+		pos := f.position(stmt)
+		f.flushComments(pos.Line)
+		f.writeBlankLine(pos.Line)
+		f.writeIndent()
+		f.writeStmt(stmt)
+		f.writeTrailingComments(pos.EndLine)
+		f.newline()
+		f.lastLine = pos.EndLine
+	}
+}
+
+func (f *Formatter) writeStmt(stmt Stmt) {
+	if loop, ok := f.runtime.ForLoops[stmt]; ok {
+		f.writeFor(loop)
+		return
+	}
+	stmt.Accept(f)
+}
+
+func (f *Formatter) writeFor(loop *ForLoop) {
+	f.write("for (")
+	switch init := loop.Initializer.(type) {
+	case nil:
+		f.write(";")
+	case *Var:
+		f.visitVarStmt(init)
+	case *Expression:
+		f.visitExpressionStmt(init)
+	}
+	if loop.Condition != nil {
+		f.write(" " + f.expr(loop.Condition))
+	}
+	f.write(";")
+	if loop.Increment != nil {
+		f.write(" " + f.expr(loop.Increment))
+	}
+	f.write(")")
+	f.writeBody(loop.Body)
+}
+
+// writeBody writes body of if, while and for statements
+func (f *Formatter) writeBody(stmt Stmt) {
+	if _, ok := f.runtime.ForLoops[stmt]; !ok && stmt.IsType(&Block{}) {
+		f.write(" ")
+		f.writeStmt(stmt)
+		return
+	}
+
+	pos := f.position(stmt)
+	f.newline()
+	f.indent++
+	f.writeIndent()
+	f.writeStmt(stmt)
+	f.writeTrailingComments(pos.EndLine)
+	f.indent--
+}
+
+func (f *Formatter) writeBlock(stmts []Stmt, startLine, endLine int) {
+	f.write("{")
+	commented := f.writeTrailingComments(startLine)
+	if len(stmts) == 0 && !f.hasCommentBefore(endLine) {
+		// the brace must not be swallowed by the comment
+		if commented {
+			f.newline()
+			f.writeIndent()
+		}
+		f.write("}")
+		return
+	}
+
+	f.newline()
+	f.indent++
+	f.lastLine = startLine
+	f.blockStart = true
+	f.writeStmts(stmts)
+	f.flushComments(endLine)
+	f.indent--
+	f.writeIndent()
+	f.write("}")
+}
+
+// flushComments writes comments placed before the line.
+// If line is negative, all remaining comments are written.
+func (f *Formatter) flushComments(line int) {
+	for len(f.comments) > 0 && (line < 0 || f.comments[0].Line < line) {
+		comment := f.comments[0]
+		f.comments = f.comments[1:]
+		f.writeBlankLine(comment.Line)
+		f.writeIndent()
+		f.write(strings.TrimSpace(comment.Lexeme))
+		f.newline()
+		f.lastLine = comment.Line
+	}
+}
+
+// writeTrailingComments writes comments placed until the line and reports whether any is written
+func (f *Formatter) writeTrailingComments(line int) bool {
+	written := false
+	for len(f.comments) > 0 && f.comments[0].Line <= line {
+		f.write(" " + strings.TrimSpace(f.comments[0].Lexeme))
+		f.comments = f.comments[1:]
+		written = true
+	}
+	return written
+}
+
+func (f *Formatter) hasCommentBefore(line int) bool {
+	return len(f.comments) > 0 && f.comments[0].Line < line
+}
+
+// writeBlankLine keeps one blank line if there are blank lines in source code
+func (f *Formatter) writeBlankLine(line int) {
+	if !f.blockStart && line > f.lastLine+1 {
+		f.newline()
+	}
+	f.blockStart = false
+}
+
+func (f *Formatter) writeIndent() {
+	f.write(strings.Repeat(" ", f.indent*indentWidth))
+}
+
+func (f *Formatter) write(s string) {
+	f.buf.WriteString(s)
+}
+
+func (f *Formatter) newline() {
+	f.buf.WriteString("\n")
+}
+
+func (f *Formatter) position(stmt Stmt) *Position {
+	if pos, ok := f.runtime.Positions[stmt]; ok {
+		return pos
+	}
+	return NewPosition(f.lastLine, 0)
+}
+
+func joinTokens(tokens []*Token) string {
+	names := make([]string, 0)
+	for _, token := range tokens {
+		names = append(names, token.Lexeme)
+	}
+	return strings.Join(names, ", ")
+}
+
+// quote returns string literal which represents s
+func quote(s string) string {
+	return native_function.Quote(s)
+}
+
+// escape returns content of string literal which represents s
+func escape(s string) string {
+	return native_function.Escape(s)
+}
@@ -0,0 +1,146 @@
+package golox_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		code     string
+	}{
+		{
+			name:     "expressions",
+			expected: "var a = -1 + (2 * 3) / 4;\nprint !true and a or \"x\\ty\\\"\";\nf(a, b).c = d.e;\n",
+			code:     "var a=-1+(2*3)/4;\nprint !true and a or \"x\\ty\\\"\";\nf(a,b).c=d.e;",
+		},
+		{
+			name:     "function and class",
+			expected: "fun f(x, y) {\n    return x + y;\n}\n\nclass A < B {\n    init(x) {\n        this.x = x;\n    }\n    m() {\n        return super.m();\n    }\n}\n",
+			code:     "fun f(x,y){return x+y;}\n\n\n\nclass A<B{init(x){this.x=x;}\nm(){return super.m();}}",
+		},
+		{
+			name:     "control flow",
+			expected: "if (a) {\n    print 1;\n} else if (b)\n    print 2;\nelse {\n    print 3;\n}\nwhile (a)\n    a = a - 1;\nfor (var i = 0; i < 3; i = i + 1) {\n    print i;\n}\nfor (;;) {}\n",
+			code:     "if(a){print 1;}else if(b)print 2;else{print 3;}\nwhile(a)a=a-1;\nfor(var i=0;i<3;i=i+1){print i;}\nfor(;;){}",
+		},
+		{
+			name:     "numbers",
+			expected: "print 0xFF + 1_000 + 1.50 + 2e3;\nprint match (x) {\n    0b11 | -0o7 => 1,\n    _ => 2,\n};\n",
+			code:     "print 0xFF+1_000+1.50+2e3;\nprint match(x){0b11|-0o7=>1,_=>2};",
+		},
+		{
+			name:     "strings",
+			expected: "print `a\\d\n`;\nprint \"\\0\\u{1b}\";\nprint match (s) {\n    `raw \\n` => 1,\n    _ => 2,\n};\n",
+			code:     "print `a\\d\n`;\nprint \"\\0\\x1b\";\nprint match(s){`raw \\n`=>1,_=>2};",
+		},
+		{
+			name:     "interpolation",
+			expected: "print \"${a}:${b + 1}${\"${c}\"} \\${d}\";\n",
+			code:     "print \"${a}:${ b+1 }${\"${c}\"} \\${d}\";",
+		},
+		{
+			name:     "lists",
+			expected: "var xs = [1, [2, 3], \"a\"[0]];\nxs[0] = xs[1][0];\n",
+			code:     "var xs=[1,[2,3,],\"a\"[ 0 ]];\nxs[0]=xs[1][0];",
+		},
+		{
+			name:     "compound assignment",
+			expected: "a += 1;\nthis.total *= a--;\nprint --a;\n",
+			code:     "a+=1;\nthis.total*=a --;\nprint --a;",
+		},
+		{
+			name:     "conditional expressions",
+			expected: "var a = b ? c ?? d : e?.f.g();\n",
+			code:     "var a=b?c??d:e?.f.g();",
+		},
+		{
+			name:     "elseif",
+			expected: "if (a) {\n    print 1;\n} else if (b) {\n    print 2;\n} else {\n    print 3;\n}\n",
+			code:     "if (a) { print 1; } elseif (b) { print 2; } else { print 3; }",
+		},
+		{
+			name:     "switch",
+			expected: "switch (x) {\n    case 1, 2: // small\n        print \"a\";\n\n        print \"b\";\n    case 3:\n    default:\n        print \"c\";\n}\n",
+			code:     "switch(x){\ncase 1,2: // small\nprint \"a\";\n\nprint \"b\";\ncase 3:\ndefault: print \"c\";}",
+		},
+		{
+			name:     "match",
+			expected: "fun f(v) {\n    return match (v) {\n        1 | -2 => \"n\",\n        [a, _] => a,\n        Point(x, y: 0) => x,\n        _ => nil,\n    };\n}\n",
+			code:     "fun f(v){return match(v){1|-2=>\"n\",[a,_]=>a,Point(x,y:0)=>x,_=>nil};}",
+		},
+		{
+			name:     "lambdas",
+			expected: "var add = (a, b) => a + b;\nvar inc = x => x + 1;\nvar noop = () => {};\nvar f = fun (a) {\n    print a;\n};\nvar g = () => {\n    return 1;\n};\n",
+			code:     "var add=(a,b)=>a+b;\nvar inc=(x)=>x+1;\nvar noop=()=>{};\nvar f=fun(a){print a;};\nvar g=()=>{return 1;};",
+		},
+		{
+			name:     "comments",
+			expected: "// head\n\nvar a = 1; // trailing\nfun f() { // brace\n    // leading\n    return 1;\n    // last\n}\n// tail\n",
+			code:     "// head\n\nvar a = 1; // trailing\nfun f() { // brace\n// leading\nreturn 1;\n// last\n}\n// tail",
+		},
+		{
+			name:     "comment in empty block",
+			expected: "fun f() { // nothing\n}\nwhile (a) { // wait\n}\n",
+			code:     "fun f() { // nothing\n}\nwhile(a){ // wait\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := golox.Format([]byte(tt.code))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestFormat_SyntaxError(t *testing.T) {
+	_, err := golox.Format([]byte("var = 1;"))
+	assert.EqualError(t, err, "[line 1] Error at '=': Expect variable name.")
+}
+
+// fmt(fmt(x)) == fmt(x)
+func TestFormat_Idempotent(t *testing.T) {
+	err := filepath.Walk("test", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".lox") {
+			return err
+		}
+
+		t.Run(path, func(t *testing.T) {
+			source, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			assertIdempotent(t, source)
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+
+	var sources = []string{
+		"fun f() { // nothing\n}",
+		"if (a) { // then\n} else { // else\n}",
+	}
+	for _, source := range sources {
+		source := source
+		t.Run(source, func(t *testing.T) {
+			assertIdempotent(t, []byte(source))
+		})
+	}
+}
+
+func assertIdempotent(t *testing.T, source []byte) {
+	once, err := golox.Format(source)
+	assert.NoError(t, err)
+	twice, err := golox.Format(once)
+	assert.NoError(t, err)
+	assert.Equal(t, string(once), string(twice))
+}
@@ -0,0 +1,314 @@
+package golox
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// mapClass is class of instances converted from Go maps
+var mapClass = NewGoLoxClass("Map", nil, map[string]*GoLoxFunction{})
+
+var (
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	nativeContextType = reflect.TypeOf((*NativeContext)(nil))
+	callableType      = reflect.TypeOf((*GoLoxCallable)(nil)).Elem()
+	listType          = reflect.TypeOf((*GoLoxList)(nil))
+	instanceType      = reflect.TypeOf((*GoLoxInstance)(nil))
+)
+
+// GoFunction is native function which wraps an arbitrary Go function by reflection.
+//
+// Arguments are converted into parameter types of the function:
+// numbers into any numeric type, lists into slices and instances into maps with string keys.
+// Returned value is converted back into Lox value, and a non-nil trailing error is raised as runtime error.
+// Results which can't be Lox values, such as structs and Go functions, are rejected.
+// A panic in the function is raised as runtime error.
+// If the first parameter is *NativeContext, the context of the call is passed to it.
+type GoFunction struct {
+	name        string
+	function    reflect.Value
+	withContext bool
+	withError   bool
+}
+
+// NewGoFunction is constructor of GoFunction
+func NewGoFunction(name string, function interface{}) (*GoFunction, error) {
+	fv := reflect.ValueOf(function)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return nil, fmt.Errorf("%s must be a function but got %T", name, function)
+	}
+
+	ft := fv.Type()
+	if ft.IsVariadic() {
+		return nil, fmt.Errorf("%s can't be variadic", name)
+	}
+	for k := 0; k < ft.NumIn(); k++ {
+		in := ft.In(k)
+		if in.Kind() == reflect.Map && in.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("parameter %d of %s must be a map with string keys but got %s", k+1, name, in)
+		}
+	}
+
+	gf := &GoFunction{
+		name:        name,
+		function:    fv,
+		withContext: ft.NumIn() > 0 && ft.In(0) == nativeContextType,
+		withError:   ft.NumOut() > 0 && ft.Out(ft.NumOut()-1) == errorType,
+	}
+	results := ft.NumOut()
+	if gf.withError {
+		results--
+	}
+	if results > 1 {
+		return nil, fmt.Errorf("%s must return at most one value besides error", name)
+	}
+	if results == 1 && !isLoxConvertible(ft.Out(0)) {
+		return nil, fmt.Errorf("result of %s can't be converted into Lox value: %s", name, ft.Out(0))
+	}
+	return gf, nil
+}
+
+// RegisterFunc defines a global native function which calls Go function
+// ex. r.RegisterFunc("repeat", strings.Repeat)
+func (r *Runtime) RegisterFunc(name string, function interface{}) error {
+	gf, err := NewGoFunction(name, function)
+	if err != nil {
+		return err
+	}
+	r.Globals.Define(name, NewContextNativeFunction(gf))
+	return nil
+}
+
+// Arity returns the number of parameters except *NativeContext
+func (gf *GoFunction) Arity() int {
+	if gf.withContext {
+		return gf.function.Type().NumIn() - 1
+	}
+	return gf.function.Type().NumIn()
+}
+
+// Call converts the arguments, calls the Go function and converts its result
+func (gf *GoFunction) Call(ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+	ft := gf.function.Type()
+	in := make([]reflect.Value, 0, ft.NumIn())
+	if gf.withContext {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	for k, argument := range arguments {
+		v, ok := fromLox(argument, ft.In(len(in)))
+		if !ok {
+			return nil, ctx.Error("Argument %d of %s must be %s but got %s.", k+1, gf.name, describeGoType(ft.In(len(in))), inspect(argument))
+		}
+		in = append(in, v)
+	}
+
+	out, recovered := gf.call(in)
+	if recovered != nil {
+		return nil, ctx.Error("%s panicked: %v", gf.name, recovered)
+	}
+	if gf.withError {
+		last := out[len(out)-1]
+		out = out[:len(out)-1]
+		if !last.IsNil() {
+			err := last.Interface().(error)
+			if _, ok := err.(*CustomError); ok {
+				return nil, err
+			}
+			return nil, ctx.Error("%s", err.Error())
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	value, ok := toLox(out[0])
+	if !ok {
+		return nil, ctx.Error("Result of %s can't be converted into Lox value: %s.", gf.name, fmt.Sprintf("%T", out[0].Interface()))
+	}
+	return value, nil
+}
+
+// call calls the Go function and recovers its panic so that the host process survives
+func (gf *GoFunction) call(in []reflect.Value) (out []reflect.Value, recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	return gf.function.Call(in), nil
+}
+
+// fromLox converts Lox value into Go value of type t
+func fromLox(value interface{}, t reflect.Type) (reflect.Value, bool) {
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func:
+			return reflect.Zero(t), true
+		}
+		return reflect.Value{}, false
+	}
+	if reflect.TypeOf(value).AssignableTo(t) {
+		return reflect.ValueOf(value), true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool:
+		v := reflect.ValueOf(value)
+		if v.Kind() != t.Kind() {
+			return reflect.Value{}, false
+		}
+		return v.Convert(t), true
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(float64)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(n).Convert(t), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 || reflect.Zero(t).OverflowInt(int64(n)) {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(int64(n)).Convert(t), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 || reflect.Zero(t).OverflowUint(uint64(n)) {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(uint64(n)).Convert(t), true
+	case reflect.Slice:
+		list, ok := value.(*GoLoxList)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		slice := reflect.MakeSlice(t, 0, len(list.Elements))
+		for _, element := range list.Elements {
+			v, ok := fromLox(element, t.Elem())
+			if !ok {
+				return reflect.Value{}, false
+			}
+			slice = reflect.Append(slice, v)
+		}
+		return slice, true
+	case reflect.Map:
+		instance, ok := value.(*GoLoxInstance)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		m := reflect.MakeMapWithSize(t, len(instance.Fields))
+		for name, field := range instance.Fields {
+			v, ok := fromLox(field, t.Elem())
+			if !ok {
+				return reflect.Value{}, false
+			}
+			m.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), v)
+		}
+		return m, true
+	}
+	return reflect.Value{}, false
+}
+
+// isLoxValueType reports whether values of t are Lox values as they are
+func isLoxValueType(t reflect.Type) bool {
+	return t == listType || t == instanceType || t.Implements(callableType)
+}
+
+// isLoxConvertible reports whether toLox may convert values of t.
+// Values of interface types are checked when they're converted.
+func isLoxConvertible(t reflect.Type) bool {
+	if isLoxValueType(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array:
+		return isLoxConvertible(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isLoxConvertible(t.Elem())
+	}
+	return false
+}
+
+// toLox converts Go value into Lox value. It reports false if v can't be converted.
+func toLox(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, true
+	}
+	if isLoxValueType(v.Type()) && v.Kind() != reflect.Interface {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, true
+		}
+		return v.Interface(), true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, true
+		}
+		return toLox(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, true
+		}
+		elements := make([]interface{}, 0, v.Len())
+		for k := 0; k < v.Len(); k++ {
+			element, ok := toLox(v.Index(k))
+			if !ok {
+				return nil, false
+			}
+			elements = append(elements, element)
+		}
+		return NewGoLoxList(elements), true
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		if v.IsNil() {
+			return nil, true
+		}
+		instance := NewGoLoxInstance(mapClass)
+		iter := v.MapRange()
+		for iter.Next() {
+			field, ok := toLox(iter.Value())
+			if !ok {
+				return nil, false
+			}
+			instance.Fields[iter.Key().String()] = field
+		}
+		return instance, true
+	}
+	return nil, false
+}
+
+// describeGoType returns description of Lox values which can be converted into t
+func describeGoType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "an integer in range of " + t.String()
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a bool"
+	case reflect.Slice:
+		return "a list of " + describeGoType(t.Elem())
+	case reflect.Map:
+		return "an instance whose fields are " + describeGoType(t.Elem())
+	}
+	return "a value of Go type " + t.String()
+}
@@ -0,0 +1,163 @@
+package golox_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+// runWithFuncs runs source with Go functions registered and returns stdout and stderr
+func runWithFuncs(t *testing.T, funcs map[string]interface{}, source string) (string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stdout = stdout
+	r.Stderr = stderr
+	for name, function := range funcs {
+		assert.NoError(t, r.RegisterFunc(name, function))
+	}
+	r.Run(bytes.NewBufferString(source))
+	return stdout.String(), stderr.String()
+}
+
+func TestRuntime_RegisterFunc(t *testing.T) {
+	funcs := map[string]interface{}{
+		"repeat": strings.Repeat,
+		"half":   func(n float32) float32 { return n / 2 },
+		"not":    func(b bool) bool { return !b },
+		"sum": func(xs []int) int {
+			total := 0
+			for _, x := range xs {
+				total += x
+			}
+			return total
+		},
+		"keys": func(m map[string]interface{}) []string {
+			keys := make([]string, 0, len(m))
+			for key := range m {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			return keys
+		},
+		"point":  func(x, y int) map[string]int { return map[string]int{"x": x, "y": y} },
+		"isNil":  func(v interface{}) bool { return v == nil },
+		"noop":   func() {},
+		"split":  func(s string) ([]string, error) { return strings.Split(s, ","), nil },
+		"twice":  func(ctx *golox.NativeContext, f interface{}) (interface{}, error) { return ctx.Call(f, 1.0) },
+		"pass":   func(list *golox.GoLoxList) *golox.GoLoxList { return list },
+		"nested": func() [][]float64 { return [][]float64{{1, 2}, {3}} },
+	}
+
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "string function", expected: "abab\n", given: `print repeat("ab", 2);`},
+		{name: "float32", expected: "1.5\n", given: "print half(3);"},
+		{name: "bool", expected: "false\n", given: "print not(true);"},
+		{name: "list into slice", expected: "6\n", given: "print sum([1, 2, 3]);"},
+		{name: "instance into map", expected: "[\"a\", \"b\"]\n", given: "class A {}\nvar a = A();\na.b = 1;\na.a = nil;\nprint keys(a);"},
+		{name: "map into instance", expected: "Map instance\n3\n", given: "var p = point(3, 4);\nprint p;\nprint p.x;"},
+		{name: "nil", expected: "true\nfalse\n", given: "print isNil(nil);\nprint isNil(0);"},
+		{name: "no result", expected: "nil\n", given: "print noop();"},
+		{name: "result with nil error", expected: "[\"a\", \"b\"]\n", given: `print split("a,b");`},
+		{name: "native context", expected: "2\n", given: "print twice(x => x * 2);"},
+		{name: "Lox value as is", expected: "true\n", given: "var xs = [1];\nprint pass(xs) == xs;"},
+		{name: "nested slices", expected: "[[1, 2], [3]]\n", given: "print nested();"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runWithFuncs(t, funcs, tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestRuntime_RegisterFunc_RuntimeError(t *testing.T) {
+	funcs := map[string]interface{}{
+		"repeat": strings.Repeat,
+		"sum":    func(xs []int) int { return len(xs) },
+		"small":  func(n int8) int8 { return n },
+		"parse":  func(s string) (int, error) { return 0, errors.New("can't parse " + s) },
+		"box":    func() interface{} { return struct{}{} },
+		"fail":   func() { panic("boom") },
+	}
+
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "wrong type", expected: "Argument 1 of repeat must be a string but got 1.\n[line 1]\n", given: "repeat(1, 2);"},
+		{name: "fraction for int", expected: "Argument 2 of repeat must be an integer in range of int but got 1.5.\n[line 2]\n", given: "\nrepeat(\"a\", 1.5);"},
+		{name: "overflow", expected: "Argument 1 of small must be an integer in range of int8 but got 128.\n[line 1]\n", given: "small(128);"},
+		{name: "wrong element", expected: "Argument 1 of sum must be a list of an integer in range of int but got [1, \"a\"].\n[line 1]\n", given: `sum([1, "a"]);`},
+		{name: "arity", expected: "Expected 2 arguments but got 1.\n[line 1]\n", given: `repeat("a");`},
+		{name: "error result", expected: "can't parse x\n[line 3]\n", given: "\n\nparse(\"x\");"},
+		{name: "unsupported dynamic result", expected: "Result of box can't be converted into Lox value: struct {}.\n[line 1]\n", given: "box();"},
+		{name: "panic", expected: "repeat panicked: strings: negative Repeat count\n[line 2]\n", given: "\nrepeat(\"a\", -1);"},
+		{name: "panic with value", expected: "fail panicked: boom\n[line 1]\n", given: "fail();"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runWithFuncs(t, funcs, tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
+
+func TestRuntime_RegisterFunc_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    interface{}
+	}{
+		{name: "not a function", expected: "f must be a function but got int", given: 1},
+		{name: "variadic", expected: "f can't be variadic", given: func(xs ...int) {}},
+		{name: "non string key", expected: "parameter 1 of f must be a map with string keys but got map[int]int", given: func(m map[int]int) {}},
+		{name: "too many results", expected: "f must return at most one value besides error", given: func() (int, int) { return 0, 0 }},
+		{name: "struct result", expected: "result of f can't be converted into Lox value: struct {}", given: func() struct{} { return struct{}{} }},
+		{name: "pointer result", expected: "result of f can't be converted into Lox value: *int", given: func() *int { return nil }},
+		{name: "func result", expected: "result of f can't be converted into Lox value: func()", given: func() func() { return nil }},
+		{name: "slice of unsupported", expected: "result of f can't be converted into Lox value: []chan int", given: func() []chan int { return nil }},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := golox.NewRuntime().RegisterFunc("f", tt.given)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestRuntime_RegisterFunc_Override(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "lib.lox"), []byte("print map(1);"), 0644))
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stdout = stdout
+	r.Stderr = stderr
+	r.BasePath = dir
+	assert.NoError(t, r.RegisterFunc("map", func(n int) int { return n + 1 }))
+
+	// registered function replaces the built-in one and survives include and subsequent runs
+	r.Run(bytes.NewBufferString(`print map(0); include "lib.lox";`))
+	r.Run(bytes.NewBufferString("print map(2);"))
+	assert.Equal(t, "", stderr.String())
+	assert.Equal(t, "1\n2\n3\n", stdout.String())
+}
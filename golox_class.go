@@ -33,7 +33,9 @@ func (lc *GoLoxClass) Call(interpreter *Interpreter, arguments []interface{}) (i
 		return nil, err
 	}
 	if initializer != nil {
-		initializer.Bind(instance).Call(interpreter, arguments)
+		if _, err := initializer.Bind(instance).Call(interpreter, arguments); err != nil {
+			return nil, err
+		}
 	}
 	return instance, nil
 }
@@ -49,3 +51,8 @@ func (lc *GoLoxClass) Arity() int {
 func (lc *GoLoxClass) String() string {
 	return lc.Name
 }
+
+// TypeName returns "class"
+func (lc *GoLoxClass) TypeName() string {
+	return "class"
+}
@@ -23,6 +23,11 @@ func (lf *GoLoxFunction) Call(interpreter *Interpreter, arguments []interface{})
 		environment.Define(param.Lexeme, arguments[i])
 	}
 
+	for _, tracer := range interpreter.Runtime.Tracers {
+		tracer.Call(interpreter, lf)
+		defer tracer.Return(interpreter, lf)
+	}
+
 	_, err := interpreter.executeBlock(lf.declaration.Body, environment)
 	if err != nil {
 		var v interface{} = err
@@ -55,6 +60,22 @@ func (lc *GoLoxFunction) Bind(instance *GoLoxInstance) *GoLoxFunction {
 	return NewGoLoxFunction(lc.declaration, environment, lc.IsInitializer)
 }
 
+// Name returns name of the function. Lambdas are named <lambda>.
+func (lf *GoLoxFunction) Name() string {
+	if lf.declaration.Name == nil {
+		return "<lambda>"
+	}
+	return lf.declaration.Name.Lexeme
+}
+
+// Declaration returns the statement which declares the function
+func (lf *GoLoxFunction) Declaration() *Function {
+	return lf.declaration
+}
+
 func (lf *GoLoxFunction) String() string {
+	if lf.declaration.Name == nil {
+		return "<fn>"
+	}
 	return "<fn " + lf.declaration.Name.Lexeme + ">"
 }
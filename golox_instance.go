@@ -25,7 +25,7 @@ func (lc *GoLoxInstance) Get(name *Token) (interface{}, error) {
 		return method.Bind(lc), nil
 	}
 
-	return nil, RuntimeError.New(name, "Undefied property '"+name.Lexeme+"'.")
+	return nil, RuntimeError.New(name, "Undefined property '"+name.Lexeme+"'.")
 }
 
 func (lc *GoLoxInstance) Set(name *Token, value interface{}) {
@@ -35,3 +35,18 @@ func (lc *GoLoxInstance) Set(name *Token, value interface{}) {
 func (lc *GoLoxInstance) String() string {
 	return lc.Klass.Name + " instance"
 }
+
+// TypeName returns "instance"
+func (lc *GoLoxInstance) TypeName() string {
+	return "instance"
+}
+
+// IsInstanceOf reports whether the instance belongs to class or its subclasses
+func (lc *GoLoxInstance) IsInstanceOf(class interface{}) bool {
+	for klass := lc.Klass; klass != nil; klass = klass.Superclass {
+		if klass == class {
+			return true
+		}
+	}
+	return false
+}
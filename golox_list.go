@@ -0,0 +1,84 @@
+package golox
+
+import "strings"
+
+// GoLoxList is list of Lox values
+type GoLoxList struct {
+	Elements []interface{}
+}
+
+// NewGoLoxList is constructor of GoLoxList
+func NewGoLoxList(elements []interface{}) *GoLoxList {
+	return &GoLoxList{
+		Elements: elements,
+	}
+}
+
+// listMethods are methods of list
+var listMethods = map[string]struct {
+	arity    int
+	function func(bm *BuiltinMethod, list *GoLoxList, arguments []interface{}) (interface{}, error)
+}{
+	"len": {0, func(bm *BuiltinMethod, list *GoLoxList, arguments []interface{}) (interface{}, error) {
+		return float64(len(list.Elements)), nil
+	}},
+	"push": {1, func(bm *BuiltinMethod, list *GoLoxList, arguments []interface{}) (interface{}, error) {
+		list.Elements = append(list.Elements, arguments[0])
+		return nil, nil
+	}},
+	"pop": {0, func(bm *BuiltinMethod, list *GoLoxList, arguments []interface{}) (interface{}, error) {
+		n := len(list.Elements)
+		if n == 0 {
+			return nil, RuntimeError.New(nil, "Can't pop from empty list.")
+		}
+		last := list.Elements[n-1]
+		list.Elements = list.Elements[:n-1]
+		return last, nil
+	}},
+}
+
+// Get returns method of the list bound to it
+func (ll *GoLoxList) Get(name *Token) (interface{}, error) {
+	method, ok := listMethods[name.Lexeme]
+	if !ok {
+		return nil, RuntimeError.New(name, "Undefined property '"+name.Lexeme+"'.")
+	}
+
+	bm := &BuiltinMethod{name: name.Lexeme, arity: method.arity}
+	bm.function = func(i *Interpreter, arguments []interface{}) (interface{}, error) {
+		return method.function(bm, ll, arguments)
+	}
+	return bm, nil
+}
+
+// Index returns the element at index
+func (ll *GoLoxList) Index(bracket *Token, index interface{}) (interface{}, error) {
+	k, err := checkIndex(bracket, index, len(ll.Elements))
+	if err != nil {
+		return nil, err
+	}
+	return ll.Elements[k], nil
+}
+
+// SetIndex replaces the element at index
+func (ll *GoLoxList) SetIndex(bracket *Token, index interface{}, value interface{}) error {
+	k, err := checkIndex(bracket, index, len(ll.Elements))
+	if err != nil {
+		return err
+	}
+	ll.Elements[k] = value
+	return nil
+}
+
+// TypeName returns "list"
+func (ll *GoLoxList) TypeName() string {
+	return "list"
+}
+
+func (ll *GoLoxList) String() string {
+	elements := make([]string, 0, len(ll.Elements))
+	for _, element := range ll.Elements {
+		elements = append(elements, inspect(element))
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
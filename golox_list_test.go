@@ -0,0 +1,58 @@
+package golox_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoLoxList(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "literal", expected: "[1, \"a\", nil, true, [2]]\n", given: `print [1, "a", nil, true, [2]];`},
+		{name: "empty", expected: "[]\n", given: `print [];`},
+		{name: "trailing comma", expected: "[1, 2]\n", given: `print [1, 2,];`},
+		{name: "index", expected: "b\n", given: `var xs = ["a", "b"]; print xs[1];`},
+		{name: "assign to index", expected: "3\n[1, 3]\n", given: `var xs = [1, 2]; print xs[1] = 3; print xs;`},
+		{name: "nested index", expected: "4\n", given: `var xs = [[1, 2], [3, 4]]; print xs[1][1];`},
+		{name: "len", expected: "2\n", given: `print [1, 2].len();`},
+		{name: "push and pop", expected: "3\n[1, 2]\n", given: `var xs = [1, 2]; xs.push(3); print xs.pop(); print xs;`},
+		{name: "identity equality", expected: "true\nfalse\n", given: `var xs = [1]; print xs == xs; print xs == [1];`},
+		{name: "evaluation order", expected: "a\nb\nc\n", given: `fun p(x) { print x; return 0; } var xs = [0]; xs[p("a")] = [p("b"), p("c")];`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestGoLoxList_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "index out of range", expected: "Index 2 is out of range for length 2.\n[line 1]\n", given: `[1, 2][2];`},
+		{name: "assign out of range", expected: "Index -1 is out of range for length 1.\n[line 1]\n", given: `var xs = [1]; xs[-1] = 0;`},
+		{name: "index beyond int", expected: "Index 100000000000000000000 is out of range for length 2.\n[line 1]\n", given: `[1, 2][1e20];`},
+		{name: "pop empty list", expected: "Can't pop from empty list.\n[line 1]\n", given: `[].pop();`},
+		{name: "unclosed list", expected: "[line 1] Error at ';': Expect ']' after list elements.\n", given: `print [1, 2;`},
+		{name: "unclosed index", expected: "[line 1] Error at ';': Expect ']' after index.\n", given: `print [1][0;`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
@@ -2,10 +2,13 @@ package golox
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/goropikari/golox/native_function"
 )
@@ -17,11 +20,6 @@ type Interpreter struct {
 
 // NewInterpreter is constructor of Interpreter
 func NewInterpreter(runtime *Runtime) *Interpreter {
-	globals := runtime.Globals
-
-	globals.Define("clock", NewNativeFunction(native_function.NewClockFunc()))
-	globals.Define("exit", NewNativeFunction(native_function.NewExitFunc()))
-
 	return &Interpreter{
 		Runtime: runtime,
 	}
@@ -35,8 +33,12 @@ func (i *Interpreter) Interpret(statements []Stmt) (string, error) {
 		var v interface{}
 		v, err = i.execute(statement)
 		s = stringfy(v)
+		if err == ErrTerminated {
+			return s, err
+		}
 		if err != nil {
 			i.Runtime.RuntimeError(err)
+			return s, err
 		}
 	}
 
@@ -53,47 +55,52 @@ func (i *Interpreter) visitBinaryExpr(expr *Binary) (interface{}, error) {
 		return nil, err
 	}
 
-	switch expr.Operator.Type {
+	return binary(expr.Operator, expr.Operator.Type, left, right)
+}
+
+// binary applies the binary operator typ to left and right. Errors are reported at operator.
+func binary(operator *Token, typ TokenType, left interface{}, right interface{}) (interface{}, error) {
+	switch typ {
 	case GreaterTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return left.(float64) > right.(float64), nil
 	case GreaterEqualTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return left.(float64) >= right.(float64), nil
 	case LessTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return left.(float64) < right.(float64), nil
 	case LessEqualTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return left.(float64) <= right.(float64), nil
 	case BangEqualTT:
-		// err := checkNumberOperands(expr.Operator, left, right)
+		// err := checkNumberOperands(operator, left, right)
 		// if err != nil {
 		// 	return nil, err
 		// }
 		// return left.(float64) != right.(float64), nil
 		return left != right, nil
 	case EqualEqualTT:
-		// err := checkNumberOperands(expr.Operator, left, right)
+		// err := checkNumberOperands(operator, left, right)
 		// if err != nil {
 		// 	return nil, err
 		// }
 		// return left.(float64) == right.(float64), nil
 		return left == right, nil
 	case MinusTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
@@ -106,19 +113,47 @@ func (i *Interpreter) visitBinaryExpr(expr *Binary) (interface{}, error) {
 			return left.(string) + right.(string), nil
 		}
 
-		return nil, RuntimeError.New(expr.Operator, "Operands must be two numbers or two strings.")
+		return nil, RuntimeError.New(operator, "Operands must be two numbers or two strings.")
 	case SlashTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return left.(float64) / right.(float64), nil
 	case StarTT:
-		err := checkNumberOperands(expr.Operator, left, right)
+		err := checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return left.(float64) * right.(float64), nil
+	case PercentTT:
+		err := checkNumberOperands(operator, left, right)
+		if err != nil {
+			return nil, err
+		}
+		// the result has the sign of left, so that a == (a ~/ b) * b + a % b
+		return math.Mod(left.(float64), right.(float64)), nil
+	case TildeSlashTT:
+		err := checkNumberOperands(operator, left, right)
+		if err != nil {
+			return nil, err
+		}
+		if right.(float64) == 0 {
+			return nil, RuntimeError.New(operator, "Integer division by zero.")
+		}
+		return math.Trunc(left.(float64) / right.(float64)), nil
+	case StarStarTT:
+		err := checkNumberOperands(operator, left, right)
+		if err != nil {
+			return nil, err
+		}
+		return math.Pow(left.(float64), right.(float64)), nil
+	case AmpersandTT, PipeTT, CaretTT, LessLessTT, GreaterGreaterTT:
+		err := checkNumberOperands(operator, left, right)
+		if err != nil {
+			return nil, err
+		}
+		return bitwise(operator, left.(float64), right.(float64))
 	}
 
 	// Unreachable.
@@ -149,7 +184,104 @@ func (i *Interpreter) visitCallExpr(expr *Call) (interface{}, error) {
 		return nil, RuntimeError.New(expr.Paren, fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), len(arguments)))
 	}
 
-	return function.Call(i, arguments)
+	var value interface{}
+	if native, ok := function.(*NativeFunction); ok {
+		value, err = native.CallAt(i, expr.Paren, arguments)
+	} else {
+		value, err = function.Call(i, arguments)
+	}
+	if err != nil && err != ErrTerminated {
+		// errors of native functions are reported at the call site
+		if e, ok := err.(*CustomError); !ok {
+			err = RuntimeError.New(expr.Paren, err.Error())
+		} else if e.Token == nil {
+			e.Token = expr.Paren
+		}
+	}
+	return value, err
+}
+
+// compoundOperators are binary operators applied by compound assignment, increment and decrement
+var compoundOperators = map[TokenType]TokenType{
+	PlusEqualTT:  PlusTT,
+	MinusEqualTT: MinusTT,
+	StarEqualTT:  StarTT,
+	SlashEqualTT: SlashTT,
+	PlusPlusTT:   PlusTT,
+	MinusMinusTT: MinusTT,
+}
+
+func (i *Interpreter) visitCompoundAssignExpr(expr *CompoundAssign) (interface{}, error) {
+	switch target := expr.Target.(type) {
+	case *Variable:
+		old, err := i.lookUpVariable(target.Name, target)
+		if err != nil {
+			return nil, err
+		}
+		value, err := i.compound(expr, old)
+		if err != nil {
+			return nil, err
+		}
+		if distance, ok := i.Runtime.Locals[target]; ok {
+			i.Runtime.Environment.AssignAt(distance, target.Name, value)
+		} else if err := i.Runtime.Globals.Assign(target.Name, value); err != nil {
+			return nil, err
+		}
+		if expr.Postfix {
+			return old, nil
+		}
+		return value, nil
+	case *Get:
+		// the object is evaluated only once
+		object, err := i.evaluate(target.Object)
+		if err != nil {
+			return nil, err
+		}
+		instance, ok := object.(*GoLoxInstance)
+		if !ok {
+			return nil, RuntimeError.New(target.Name, "Only instances have fields.")
+		}
+		old, err := instance.Get(target.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := i.compound(expr, old)
+		if err != nil {
+			return nil, err
+		}
+		instance.Set(target.Name, value)
+		if expr.Postfix {
+			return old, nil
+		}
+		return value, nil
+	}
+
+	// Unreachable
+	return nil, RuntimeError.New(expr.Operator, "Invalid assignment target.")
+}
+
+// compound evaluates right hand side of compound assignment and applies its operator to old value
+func (i *Interpreter) compound(expr *CompoundAssign, old interface{}) (interface{}, error) {
+	operand, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	return binary(expr.Operator, compoundOperators[expr.Operator.Type], old, operand)
+}
+
+// errNilChain short-circuits optional chain up to the enclosing OptionalChain
+var errNilChain = errors.New("optional chain short-circuited")
+
+func (i *Interpreter) visitConditionalExpr(expr *Conditional) (interface{}, error) {
+	condition, err := i.evaluate(expr.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.isTruthy(condition) {
+		return i.evaluate(expr.ThenBranch)
+	}
+	return i.evaluate(expr.ElseBranch)
 }
 
 func (i *Interpreter) visitGetExpr(expr *Get) (interface{}, error) {
@@ -157,13 +289,37 @@ func (i *Interpreter) visitGetExpr(expr *Get) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	if _, ok := object.(*GoLoxInstance); ok {
-		return object.(*GoLoxInstance).Get(expr.Name)
+	if object == nil && expr.Optional {
+		return nil, errNilChain
+	}
+	switch v := object.(type) {
+	case *GoLoxInstance:
+		return v.Get(expr.Name)
+	case *GoLoxList:
+		return v.Get(expr.Name)
+	case string:
+		return stringMethod(v, expr.Name)
 	}
 
 	return nil, RuntimeError.New(expr.Name, "Only instances have properties.")
 }
 
+func (i *Interpreter) visitLambdaExpr(expr *Lambda) (interface{}, error) {
+	return NewGoLoxFunction(expr.Function, i.Runtime.Environment, false), nil
+}
+
+func (i *Interpreter) visitListExpr(expr *List) (interface{}, error) {
+	elements := make([]interface{}, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		value, err := i.evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, value)
+	}
+	return NewGoLoxList(elements), nil
+}
+
 func (i *Interpreter) visitLiteralExpr(expr *Literal) (interface{}, error) {
 	return expr.Value, nil
 }
@@ -174,7 +330,11 @@ func (i *Interpreter) visitLogicalExpr(expr *Logical) (interface{}, error) {
 		return nil, err
 	}
 
-	if expr.Operator.Type == OrTT {
+	if expr.Operator.Type == QuestionQuestionTT {
+		if left != nil {
+			return left, nil
+		}
+	} else if expr.Operator.Type == OrTT {
 		if i.isTruthy(left) {
 			return left, nil
 		}
@@ -187,6 +347,112 @@ func (i *Interpreter) visitLogicalExpr(expr *Logical) (interface{}, error) {
 	return i.evaluate(expr.Right)
 }
 
+func (i *Interpreter) visitMatchExpr(expr *Match) (interface{}, error) {
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arm := range expr.Arms {
+		bindings := make(map[string]interface{})
+		ok, err := i.matchPattern(arm.Pattern, value, bindings)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		environment := NewEnvironment(i.Runtime.Environment)
+		for name, v := range bindings {
+			environment.Define(name, v)
+		}
+		previous := i.Runtime.Environment
+		i.Runtime.Environment = environment
+		result, err := i.evaluate(arm.Body)
+		i.Runtime.Environment = previous
+		return result, err
+	}
+
+	return nil, RuntimeError.New(expr.Keyword, "No match arm matches "+inspect(value)+".")
+}
+
+// matchPattern reports whether value matches pattern, and collects variables bound by the pattern into bindings
+func (i *Interpreter) matchPattern(pattern Pattern, value interface{}, bindings map[string]interface{}) (bool, error) {
+	switch p := pattern.(type) {
+	case *LiteralPattern:
+		return p.Value == value, nil
+	case *WildcardPattern:
+		return true, nil
+	case *BindingPattern:
+		bindings[p.Name.Lexeme] = value
+		return true, nil
+	case *ListPattern:
+		list, ok := value.(*GoLoxList)
+		if !ok || len(list.Elements) != len(p.Elements) {
+			return false, nil
+		}
+		for k, element := range p.Elements {
+			ok, err := i.matchPattern(element, list.Elements[k], bindings)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case *ClassPattern:
+		class, err := i.evaluate(p.Class)
+		if err != nil {
+			return false, err
+		}
+		klass, ok := class.(*GoLoxClass)
+		if !ok {
+			return false, RuntimeError.New(p.Class.Name, "Only classes can be used in class patterns.")
+		}
+		instance, ok := value.(*GoLoxInstance)
+		if !ok || !instance.IsInstanceOf(klass) {
+			return false, nil
+		}
+		for _, field := range p.Fields {
+			v, ok := instance.Fields[field.Name.Lexeme]
+			if !ok {
+				return false, nil
+			}
+			ok, err := i.matchPattern(field.Pattern, v, bindings)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case *AlternativePattern:
+		for _, alternative := range p.Alternatives {
+			// bindings of failed alternatives are discarded
+			candidate := make(map[string]interface{})
+			ok, err := i.matchPattern(alternative, value, candidate)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				for name, v := range candidate {
+					bindings[name] = v
+				}
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// Unreachable
+	return false, nil
+}
+
+func (i *Interpreter) visitOptionalChainExpr(expr *OptionalChain) (interface{}, error) {
+	value, err := i.evaluate(expr.Expression)
+	if err == errNilChain {
+		return nil, nil
+	}
+	return value, err
+}
+
 func (i *Interpreter) visitSetExpr(expr *Set) (interface{}, error) {
 	object, err := i.evaluate(expr.Object)
 	if err != nil {
@@ -207,6 +473,31 @@ func (i *Interpreter) visitSetExpr(expr *Set) (interface{}, error) {
 	return value, nil
 }
 
+func (i *Interpreter) visitSetIndexExpr(expr *SetIndex) (interface{}, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := object.(*GoLoxList)
+	if !ok {
+		return nil, RuntimeError.New(expr.Bracket, "Only list elements can be assigned.")
+	}
+
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := list.SetIndex(expr.Bracket, index, value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
 func (i *Interpreter) visitSuperExpr(expr *Super) (interface{}, error) {
 	distance := i.Runtime.Locals[expr]
 	sc, _ := i.Runtime.Environment.GetAt(distance, "super")
@@ -217,7 +508,7 @@ func (i *Interpreter) visitSuperExpr(expr *Super) (interface{}, error) {
 	method, _ := superclass.FindMethod(expr.Method.Lexeme)
 
 	if method == nil {
-		return nil, RuntimeError.New(expr.Method, "Undifined property '"+expr.Method.Lexeme+"'.")
+		return nil, RuntimeError.New(expr.Method, "Undefined property '"+expr.Method.Lexeme+"'.")
 	}
 
 	return method.Bind(object), nil
@@ -231,6 +522,37 @@ func (i *Interpreter) visitGroupingExpr(expr *Grouping) (interface{}, error) {
 	return i.evaluate(expr.Expression)
 }
 
+func (i *Interpreter) visitIndexExpr(expr *Index) (interface{}, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := object.(type) {
+	case *GoLoxList:
+		return v.Index(expr.Bracket, index)
+	case string:
+		return indexString(v, expr.Bracket, index)
+	}
+	return nil, RuntimeError.New(expr.Bracket, "Only lists and strings can be indexed.")
+}
+
+func (i *Interpreter) visitInterpolationExpr(expr *Interpolation) (interface{}, error) {
+	var b strings.Builder
+	for _, part := range expr.Parts {
+		value, err := i.evaluate(part)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(stringfy(value))
+	}
+	return b.String(), nil
+}
+
 func (i *Interpreter) visitUnaryExpr(expr *Unary) (interface{}, error) {
 	right, err := i.evaluate(expr.Right)
 	if err != nil {
@@ -246,6 +568,16 @@ func (i *Interpreter) visitUnaryExpr(expr *Unary) (interface{}, error) {
 			return nil, err
 		}
 		return -right.(float64), nil
+	case TildeTT:
+		err := checkNumberOperand(expr.Operator, right)
+		if err != nil {
+			return nil, err
+		}
+		n, err := integralPart(expr.Operator, right.(float64))
+		if err != nil {
+			return nil, err
+		}
+		return float64(^n), nil
 	}
 
 	// Unreachable
@@ -276,7 +608,45 @@ func checkNumberOperands(operator *Token, left interface{}, right interface{}) e
 		reflect.ValueOf(right).Kind() == reflect.Float64 {
 		return nil
 	}
-	return RuntimeError.New(operator, "Operands must be a number.")
+	return RuntimeError.New(operator, "Operands must be numbers.")
+}
+
+// bitwise applies bitwise operator to integral parts of left and right
+func bitwise(operator *Token, left float64, right float64) (interface{}, error) {
+	l, err := integralPart(operator, left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := integralPart(operator, right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator.Type {
+	case AmpersandTT:
+		return float64(l & r), nil
+	case PipeTT:
+		return float64(l | r), nil
+	case CaretTT:
+		return float64(l ^ r), nil
+	}
+
+	if r < 0 {
+		return nil, RuntimeError.New(operator, "Shift count must be non-negative.")
+	}
+	if operator.Type == LessLessTT {
+		return float64(l << uint64(r)), nil
+	}
+	return float64(l >> uint64(r)), nil
+}
+
+// integralPart truncates n into an integer for bitwise operators
+func integralPart(operator *Token, n float64) (int64, error) {
+	// conversion of a value outside int64 is implementation-defined in Go
+	if math.IsNaN(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+		return 0, RuntimeError.New(operator, "Operands of bitwise operators must be finite numbers in range of 64-bit integers.")
+	}
+	return int64(n), nil
 }
 
 func (i *Interpreter) isTruthy(object interface{}) bool {
@@ -303,6 +673,11 @@ func (i *Interpreter) Resolve(expr Expr, depth int) error {
 }
 
 func (i *Interpreter) execute(stmt Stmt) (interface{}, error) {
+	for _, tracer := range i.Runtime.Tracers {
+		if err := tracer.Statement(i, stmt); err != nil {
+			return nil, err
+		}
+	}
 	return stmt.Accept(i)
 }
 
@@ -324,6 +699,10 @@ func (i *Interpreter) visitBlockStmt(stmt *Block) (interface{}, error) {
 	return i.executeBlock(stmt.Statements, NewEnvironment(i.Runtime.Environment))
 }
 
+func (i *Interpreter) visitCaseStmt(stmt *Case) (interface{}, error) {
+	return i.executeBlock(stmt.Body, NewEnvironment(i.Runtime.Environment))
+}
+
 func (i *Interpreter) visitClassStmt(stmt *Class) (interface{}, error) {
 	var superclass *GoLoxClass = nil
 	if stmt.Superclass != nil {
@@ -404,12 +783,13 @@ func (i *Interpreter) visitIncludeStmt(stmt *Include) (interface{}, error) {
 		return nil, RuntimeError.New(stmt.Path, err.Error())
 	}
 
-	previousBasePath := i.Runtime.BasePath
+	previousBasePath, previousFile := i.Runtime.BasePath, i.Runtime.File
 	i.Runtime.BasePath = filepath.Dir(path)
+	i.Runtime.File = path
 
 	i.Runtime.Run(bytes.NewBuffer(source))
 
-	i.Runtime.BasePath = previousBasePath
+	i.Runtime.BasePath, i.Runtime.File = previousBasePath, previousFile
 
 	return nil, nil
 }
@@ -420,7 +800,7 @@ func (i *Interpreter) visitPrintStmt(stmt *Print) (interface{}, error) {
 		return nil, err
 	}
 
-	fmt.Println(stringfy(value))
+	fmt.Fprintln(i.Runtime.Stdout, stringfy(value))
 	return nil, nil
 }
 
@@ -438,11 +818,48 @@ func (i *Interpreter) visitReturnStmt(stmt *Return) (interface{}, error) {
 }
 
 func (i *Interpreter) visitWhileStmt(stmt *While) (interface{}, error) {
-	v, _ := i.evaluate(stmt.Condition)
-	for ; i.isTruthy(v); v, _ = i.evaluate(stmt.Condition) {
-		i.execute(stmt.Body)
+	for {
+		v, err := i.evaluate(stmt.Condition)
+		if err != nil {
+			return nil, err
+		}
+		if !i.isTruthy(v) {
+			return nil, nil
+		}
+		if _, err := i.execute(stmt.Body); err != nil {
+			return nil, err
+		}
 	}
+}
 
+// visitSwitchStmt runs the first case which has a value equal to the switch value.
+// The default arm runs only when no case matches wherever it's placed. There is no fallthrough.
+func (i *Interpreter) visitSwitchStmt(stmt *Switch) (interface{}, error) {
+	value, err := i.evaluate(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultCase *Case
+	for _, c := range stmt.Cases {
+		if c.Values == nil {
+			defaultCase = c
+			continue
+		}
+		for _, expr := range c.Values {
+			candidate, err := i.evaluate(expr)
+			if err != nil {
+				return nil, err
+			}
+			if value == candidate {
+				return i.execute(c)
+			}
+		}
+	}
+
+	if defaultCase != nil {
+		return i.execute(defaultCase)
+	}
 	return nil, nil
 }
 
@@ -505,10 +922,16 @@ func isString(v interface{}) bool {
 	return isType(v, reflect.String)
 }
 
+// Stringfy returns string representation of Lox value
+func Stringfy(object interface{}) string {
+	return stringfy(object)
+}
+
 func stringfy(object interface{}) string {
-	if object == nil {
-		return "nil"
-	}
+	return native_function.Stringify(object)
+}
 
-	return fmt.Sprint(object)
+// inspect returns representation of value which distinguishes strings from other values
+func inspect(value interface{}) string {
+	return native_function.Inspect(value)
 }
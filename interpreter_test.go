@@ -124,6 +124,7 @@ func TestInterpreter(t *testing.T) {
 							},
 						),
 						golox.NewToken(golox.IdentifierTT, "x", nil, 4),
+						false,
 					),
 				),
 			},
@@ -188,6 +189,7 @@ func TestInterpreter(t *testing.T) {
 		// 					},
 		// 				),
 		// 				golox.NewToken(golox.IdentifierTT, "x", nil, 6),
+		// 				false,
 		// 			),
 		// 		),
 		// 	},
@@ -328,3 +330,330 @@ func TestInterpreter_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestInterpreter_Interpolation(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "number", expected: "total: 4.5\n", given: `var count = 3; print "total: ${count * 1.5}";`},
+		{name: "values", expected: "nil true s <fn f> A instance\n", given: `fun f() {} class A {} print "${nil} ${true} ${"s"} ${f} ${A()}";`},
+		{name: "nested", expected: "a[b2c]d\n", given: `print "a${"[b${1 + 1}c]"}d";`},
+		{name: "escaped", expected: "${x}\n", given: `print "\${x}";`},
+		{name: "closure", expected: "hi bob\n", given: `fun greet(name) { fun g() { return "hi ${name}"; } return g; } print greet("bob")();`},
+		{name: "runtime error at line of expression", expected: "Operands must be two numbers or two strings.\n[line 3]\n", given: "print \"a${\n  1\n  + nil}\";"},
+		{name: "empty interpolation", expected: "[line 1] Error: Expect expression in string interpolation.\n", given: `print "a${}b";`},
+		{name: "empty interpolation after expression", expected: "[line 2] Error: Expect expression in string interpolation.\n", given: "\nprint \"${1}${}b${2}\";"},
+		{name: "missing operand in interpolation", expected: "[line 2] Error at '}': Expect expression.\n", given: "\nprint \"${1 +}\";"},
+		{name: "unclosed interpolation", expected: "[line 1] Error at ';': Expect '}' after interpolated expression.\n", given: `print "a${1;`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
+
+func TestInterpreter_Operators(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "modulo", expected: "1\n-1\n1.5\n", given: "print 7 % 3; print -7 % 3; print 5.5 % 2;"},
+		{name: "integer division", expected: "2\n-2\n", given: "print 7 ~/ 3; print -7 ~/ 3;"},
+		{name: "division identity", expected: "true\n", given: "var a = -7; var b = 3; print a == (a ~/ b) * b + a % b;"},
+		{name: "exponent", expected: "1024\n0.5\n", given: "print 2 ** 10; print 2 ** -1;"},
+		{name: "exponent is right-associative", expected: "512\n", given: "print 2 ** 3 ** 2;"},
+		{name: "exponent binds tighter than unary minus", expected: "-4\n4\n", given: "print -2 ** 2; print (-2) ** 2;"},
+		{name: "exponent binds tighter than factor", expected: "18\n", given: "print 2 * 3 ** 2;"},
+		{name: "bitwise", expected: "4\n7\n3\n-6\n", given: "print 6 & 5; print 6 | 5; print 6 ^ 5; print ~5;"},
+		{name: "bitwise uses integral part", expected: "2\n", given: "print 6.9 & 3.2;"},
+		{name: "bitwise on smallest int64", expected: "true\n", given: "print (-9223372036854775808 | 0) == -9223372036854775808;"},
+		{name: "shift", expected: "40\n2\n-3\n", given: "print 5 << 3; print 5 >> 1; print -5 >> 1;"},
+		{name: "bitwise precedence", expected: "7\ntrue\n", given: "print 1 | 2 ^ 4 & 7 << 0; print 1 + 2 << 1 == 6;"},
+		{name: "operands must be numbers", expected: "Operands must be numbers.\n[line 1]\n", given: `print "a" % 2;`},
+		{name: "operand must be a number", expected: "Operand must be a number.\n[line 1]\n", given: `print ~"a";`},
+		{name: "integer division by zero", expected: "Integer division by zero.\n[line 1]\n", given: "print 1 ~/ 0;"},
+		{name: "negative shift count", expected: "Shift count must be non-negative.\n[line 1]\n", given: "print 1 << -1;"},
+		{name: "bitwise on infinity", expected: "Operands of bitwise operators must be finite numbers in range of 64-bit integers.\n[line 1]\n", given: "print (1 / 0) | 1;"},
+		{name: "bitwise on huge number", expected: "Operands of bitwise operators must be finite numbers in range of 64-bit integers.\n[line 2]\n", given: "\nprint 1e300 | 0;"},
+		{name: "bitwise on 2^63", expected: "Operands of bitwise operators must be finite numbers in range of 64-bit integers.\n[line 1]\n", given: "print ~9223372036854775808;"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
+
+func TestInterpreter_CompoundAssignment(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "variable", expected: "3\n6\n4\n2\n2\n", given: "var a = 1; print a += 2; print a *= 2; print a -= 2; print a /= 2; print a;"},
+		{name: "string", expected: "ab\n", given: `var s = "a"; s += "b"; print s;`},
+		{name: "right-associative", expected: "3\n2\n", given: "var a = 1; var b = 1; a += b += 1; print a; print b;"},
+		{name: "increment and decrement", expected: "1\n2\n2\n1\n", given: "var a = 1; print a++; print a; print a--; print a;"},
+		{name: "double negation", expected: "3\n-3\n", given: "var a = 3; print --a; print ---a;"},
+		{name: "minus negation", expected: "5\n5\n1\n", given: "var a = 3; var b = 2; print a--b; print a-- b; print a-(--b);"},
+		{name: "decrement before operator", expected: "3\n2\n", given: "var a = 3; var b = a-- * 1; print b; print a;"},
+		{name: "decrement of property", expected: "1\n", given: "class A {} var o = A(); o.n = 2; o.n--; print o.n;"},
+		{name: "local", expected: "11\n1\n", given: "var a = 1; { var a = 10; a++; print a; } print a;"},
+		{name: "closure", expected: "1\n2\n", given: "fun counter() { var n = 0; fun inc() { n += 1; return n; } return inc; } var c = counter(); print c(); print c();"},
+		{name: "for loop", expected: "0\n1\n2\n", given: "for (var i = 0; i < 3; i++) print i;"},
+		{
+			name:     "property",
+			expected: "15\n16\n15\n",
+			given:    "class A { init() { this.total = 10; } add(x) { this.total += x; } } var a = A(); a.add(5); print a.total; print a.total++ + 1; print a.total - 1;",
+		},
+		{
+			name:     "object is evaluated once",
+			expected: "1\n2\n",
+			given:    "class A {} var a = A(); a.n = 1; var calls = 0; fun get() { calls++; return a; } get().n += 1; print calls; print a.n;",
+		},
+		{name: "operands must be numbers", expected: "Operands must be numbers.\n[line 1]\n", given: `var a = "s"; a -= 1;`},
+		{name: "undefined variable", expected: "Undefined variable 'b'.\n[line 1]\n", given: "b += 1;"},
+		{name: "undefined property", expected: "Undefined property 'x'.\n[line 1]\n", given: "class A {} A().x += 1;"},
+		{name: "field of non instance", expected: "Only instances have fields.\n[line 1]\n", given: "var a = 1; a.x += 1;"},
+		{name: "invalid target", expected: "[line 1] Error at '+=': Invalid assignment target.\n", given: "1 += 2;"},
+		{name: "invalid increment target", expected: "[line 1] Error at '++': Invalid increment target.\n", given: "(a)++;"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
+
+func TestInterpreter_ConditionalExpressions(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "ternary", expected: "yes\nno\n", given: `print true ? "yes" : "no"; print nil ? "yes" : "no";`},
+		{name: "ternary is right-associative", expected: "b\n", given: `var n = 2; print n == 1 ? "a" : n == 2 ? "b" : "c";`},
+		{name: "ternary is lazy", expected: "1\n", given: `fun boom() { print "boom"; } print true ? 1 : boom();`},
+		{name: "ternary binds looser than or", expected: "1\n", given: "print false or true ? 1 : 2;"},
+		{name: "ternary in assignment", expected: "2\n", given: "var a; a = false ? 1 : 2; print a;"},
+		{name: "coalesce", expected: "d\nfalse\n0\n", given: `print nil ?? "d"; print false ?? "d"; print 0 ?? "d";`},
+		{name: "coalesce is lazy", expected: "1\n", given: `fun boom() { print "boom"; } print 1 ?? boom();`},
+		{name: "coalesce chain", expected: "3\n", given: "print nil ?? nil ?? 3;"},
+		{name: "optional field", expected: "nil\n1\n", given: "class A {} var a = A(); a.x = 1; var n = nil; print n?.x; print a?.x;"},
+		{name: "optional method", expected: "nil\nhi\n", given: `class A { hi() { return "hi"; } } var n; print n?.hi(); print A()?.hi();`},
+		{name: "optional chain short-circuits", expected: "nil\n", given: `fun boom() { print "boom"; } var n; print n?.a.b(boom()).c;`},
+		{name: "optional chain with coalesce", expected: "default\n", given: `var n; print n?.name ?? "default";`},
+		{name: "optional chain on list", expected: "2\n", given: "var xs = [1, 2]; print xs?.len();"},
+		{name: "undefined property", expected: "Undefined property 'x'.\n[line 1]\n", given: "class A {} print A()?.x;"},
+		{name: "property of nil", expected: "Only instances have properties.\n[line 1]\n", given: "class A {} var a = A(); a.b = nil; print a?.b.c;"},
+		{name: "missing colon", expected: "[line 1] Error at ';': Expect ':' after then branch of conditional expression.\n", given: "print true ? 1;"},
+		{name: "optional chain is not assignable", expected: "[line 1] Error at '=': Invalid assignment target.\n", given: "var a; a?.b = 1;"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
+
+func TestInterpreter_Elseif(t *testing.T) {
+	source := `fun grade(n) {
+  if (n >= 90) return "A";
+  elseif (n >= 80) return "B";
+  elseif (n >= 70) { return "C"; }
+  else return "D";
+}
+print grade(95);
+print grade(85);
+print grade(75);
+print grade(10);
+if (false) print 1; elseif (false) print 2;
+print "done";`
+
+	stdout, stderr := runScript(source)
+	assert.Equal(t, "", stderr)
+	assert.Equal(t, "A\nB\nC\nD\ndone\n", stdout)
+
+	_, stderr = runScript("if (true) print 1; elseif true print 2;")
+	assert.Equal(t, "[line 1] Error at 'true': Expect '(' after 'elseif'.\n", stderr)
+}
+
+func TestInterpreter_Switch(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{
+			name:     "multiple values",
+			expected: "small\nsmall\nthree\nother\n",
+			given: `fun f(n) {
+  switch (n) {
+    case 1, 2: print "small";
+    case 3: print "three";
+    default: print "other";
+  }
+}
+f(1); f(2); f(3); f(4);`,
+		},
+		{name: "no fallthrough", expected: "a\n", given: `switch (1) { case 1: print "a"; case 2: print "b"; }`},
+		{name: "no match without default", expected: "done\n", given: `switch ("x") { case "y": print "y"; } print "done";`},
+		{name: "default anywhere", expected: "b\nd\n", given: `fun f(n) { switch (n) { default: print "d"; case 2: print "b"; } } f(2); f(3);`},
+		{name: "strings", expected: "hi\n", given: `var s = "h" + "i"; switch (s) { case "hi": print "hi"; }`},
+		{name: "values are evaluated lazily", expected: "1\n", given: `fun boom() { print "boom"; } switch (1) { case 1: print 1; case boom(): print 2; }`},
+		{name: "case scope", expected: "2\n1\n", given: "var a = 1; switch (a) { case 1: var a = 2; print a; } print a;"},
+		{name: "return from case", expected: "one\n", given: `fun f(n) { switch (n) { case 1: return "one"; } return "other"; } print f(1);`},
+		{name: "duplicate default", expected: "[line 1] Error at 'default': Switch can't have more than one default.\n", given: "switch (1) { default: default: }"},
+		{name: "missing colon", expected: "[line 1] Error at 'print': Expect ':' after case values.\n", given: "switch (1) { case 1 print 1; }"},
+		{name: "statement before case", expected: "[line 1] Error at 'print': Expect 'case' or 'default' in switch body.\n", given: "switch (1) { print 1; }"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
+
+func TestInterpreter_Match(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{
+			name:     "literals and alternatives",
+			expected: "one\nxy\nxy\nneg\nnil\nother\n",
+			given: `fun f(v) { return match (v) { 1 => "one", "x" | "y" => "xy", -1 => "neg", nil => "nil", _ => "other" }; }
+print f(1); print f("x"); print f("y"); print f(-1); print f(nil); print f(true);`,
+		},
+		{
+			name:     "list",
+			expected: "3\nempty\n[1]\n",
+			given: `fun f(v) { return match (v) { [a, b] => a + b, [] => "empty", other => other }; }
+print f([1, 2]); print f([]); print f([1]);`,
+		},
+		{
+			name:     "nested list",
+			expected: "2\n",
+			given:    "print match ([1, [2, 3]]) { [1, [x, _]] => x, _ => 0 };",
+		},
+		{
+			name:     "instance",
+			expected: "on x axis 3\n1,2\nnot a point\n",
+			given: `class Point { init(x, y) { this.x = x; this.y = y; } }
+class Point3 < Point {}
+fun f(v) {
+  return match (v) {
+    Point(x, y: 0) => "on x axis ${x}",
+    Point(x, y) => "${x},${y}",
+    _ => "not a point",
+  };
+}
+print f(Point(3, 0)); print f(Point3(1, 2)); print f(1);`,
+		},
+		{
+			name:     "missing field doesn't match",
+			expected: "no z\n",
+			given:    `class P {} var p = P(); p.x = 1; print match (p) { P(z) => z, P(x) => "no z" };`,
+		},
+		{
+			name:     "alternatives bind the same variables",
+			expected: "1\n2\n",
+			given:    "fun f(v) { return match (v) { [x] | [x, _] => x }; } print f([1]); print f([2, 3]);",
+		},
+		{
+			name:     "bindings are scoped to the arm",
+			expected: "2\n1\n",
+			given:    "var x = 1; print match (2) { x => x }; print x;",
+		},
+		{
+			name:     "closure captures binding",
+			expected: "5\n",
+			given:    "fun f(v) { return match (v) { [n] => n }; } fun g() { var k = [5]; fun h() { return match (k) { [n] => n }; } return h; } print g()();",
+		},
+		{
+			name:     "no arm matches",
+			expected: "No match arm matches \"z\".\n[line 1]\n",
+			given:    `print match ("z") { "x" => 1 };`,
+		},
+		{
+			name:     "class pattern of non class",
+			expected: "Only classes can be used in class patterns.\n[line 1]\n",
+			given:    "var A = 1; print match (1) { A(x) => x };",
+		},
+		{
+			name:     "alternatives binding different variables",
+			expected: "[line 1] Error at '|': Alternatives of a pattern must bind the same variables.\n",
+			given:    "print match (1) { [x] | [y] => 1 };",
+		},
+		{
+			name:     "duplicate binding",
+			expected: "[line 1] Error at 'x': Already a variable with this name in this scope.\n",
+			given:    "fun f() { return match (1) { [x, x] => x }; }",
+		},
+		{
+			name:     "missing arrow",
+			expected: "[line 1] Error at '1': Expect '=>' after pattern.\n",
+			given:    "print match (1) { _ 1 };",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
+
+func TestInterpreter_Lambda(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "fun expression", expected: "3\n", given: "var add = fun (a, b) { return a + b; }; print add(1, 2);"},
+		{name: "arrow", expected: "3\n", given: "var add = (a, b) => a + b; print add(1, 2);"},
+		{name: "arrow with single parameter", expected: "4\n", given: "var double = x => x * 2; print double(2);"},
+		{name: "arrow without parameters", expected: "1\n", given: "var one = () => 1; print one();"},
+		{name: "arrow with block", expected: "2\n", given: "var f = (a) => { var b = a + 1; return b; }; print f(1);"},
+		{name: "immediately invoked", expected: "5\n", given: "print ((x) => x + 2)(3);"},
+		{name: "immediately invoked fun statement", expected: "hi\n", given: `fun () { print "hi"; }();`},
+		{name: "callback", expected: "10\n", given: "fun apply(f, x) { return f(x); } print apply(x => x * 10, 1);"},
+		{name: "closure", expected: "1\n2\n", given: "fun counter() { var n = 0; return () => n += 1; } var c = counter(); print c(); print c();"},
+		{name: "curried", expected: "3\n", given: "var add = a => b => a + b; print add(1)(2);"},
+		{name: "this in method", expected: "7\n", given: "class A { init() { this.n = 7; } get() { return () => this.n; } } print A().get()();"},
+		{name: "grouping is not lambda", expected: "3\n", given: "var a = 1; print (a) + 2;"},
+		{name: "print lambda", expected: "<fn>\nfunction\n", given: "print () => 1; print type(fun () {});"},
+		{name: "arity", expected: "Expected 2 arguments but got 1.\n[line 1]\n", given: "((a, b) => a)(1);"},
+		{name: "missing body", expected: "[line 1] Error at ';': Expect '{' before lambda body.\n", given: "var f = fun (a);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stdout+stderr)
+		})
+	}
+}
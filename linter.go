@@ -0,0 +1,152 @@
+package golox
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Warning is a suspicious piece of code found by Linter
+type Warning struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// NewWarning is constructor of Warning
+func NewWarning(line, column int, message string) *Warning {
+	return &Warning{
+		Line:    line,
+		Column:  column,
+		Message: message,
+	}
+}
+
+// String stringfy Warning
+func (w *Warning) String() string {
+	return fmt.Sprintf("%d:%d: %s", w.Line, w.Column, w.Message)
+}
+
+// Linter is static checker which reports unused variables, unreachable code
+// and so on without executing the program. It's built on scope data recorded by Resolver.
+type Linter struct {
+	runtime  *Runtime
+	globals  map[string]bool
+	warnings []*Warning
+}
+
+// NewLinter is constructor of Linter
+func NewLinter(runtime *Runtime) *Linter {
+	return &Linter{
+		runtime:  runtime,
+		globals:  make(map[string]bool),
+		warnings: make([]*Warning, 0),
+	}
+}
+
+// Lint checks given statements and returns warnings sorted by position
+func (l *Linter) Lint(stmts []Stmt) []*Warning {
+	l.warnings = make([]*Warning, 0)
+	// natives aren't collected so that locals named like them don't warn shadowing
+	l.globals = make(map[string]bool)
+	l.collectGlobals(stmts, l.runtime.BasePath, make(map[string]bool))
+
+	resolver := NewResolver(l.runtime, nil)
+	// errors of resolver, such as 'this' outside of a class, are reported as warnings
+	resolver.report = l.warnToken
+	resolver.ResolveStmts(stmts)
+
+	for _, stmt := range resolver.unreachable {
+		l.warnStmt(stmt, "Unreachable code.")
+	}
+	l.lintDeclarations(resolver)
+	l.lintAssignments(resolver)
+
+	sort.SliceStable(l.warnings, func(i, j int) bool {
+		if l.warnings[i].Line != l.warnings[j].Line {
+			return l.warnings[i].Line < l.warnings[j].Line
+		}
+		return l.warnings[i].Column < l.warnings[j].Column
+	})
+
+	return l.warnings
+}
+
+// collectGlobals collects top-level declarations including the ones in included files
+func (l *Linter) collectGlobals(stmts []Stmt, basePath string, visited map[string]bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *Var:
+			l.globals[s.Name.Lexeme] = true
+		case *Function:
+			l.globals[s.Name.Lexeme] = true
+		case *Class:
+			l.globals[s.Name.Lexeme] = true
+		case *Include:
+			path := filepath.Join(basePath, s.Path.Literal.(string))
+			if visited[path] {
+				continue
+			}
+			visited[path] = true
+
+			source, err := os.ReadFile(path)
+			if err != nil {
+				l.warnToken(s.Path, "Can't read included file: "+err.Error())
+				continue
+			}
+			included := NewRuntime().Parse(bytes.NewBuffer(source))
+			l.collectGlobals(included, filepath.Dir(path), visited)
+		}
+	}
+}
+
+// lintDeclarations reports unused and shadowing local declarations
+func (l *Linter) lintDeclarations(resolver *Resolver) {
+	// assignment doesn't count as use of the variable
+	used := make(map[*Token]bool)
+	for name := range resolver.bindings {
+		if resolver.assignments[name] {
+			continue
+		}
+		if decl, ok := resolver.Definition(name); ok && decl != name {
+			used[decl] = true
+		}
+	}
+
+	for decl, kind := range resolver.kinds {
+		if resolver.shadows[decl] || l.globals[decl.Lexeme] {
+			l.warnToken(decl, "'"+decl.Lexeme+"' shadows a variable in an enclosing scope.")
+		}
+		if !used[decl] && !strings.HasPrefix(decl.Lexeme, "_") {
+			l.warnToken(decl, "Unused "+kind+" '"+decl.Lexeme+"'.")
+		}
+	}
+}
+
+// lintAssignments reports assignments to variables declared nowhere
+func (l *Linter) lintAssignments(resolver *Resolver) {
+	for name := range resolver.assignments {
+		if _, ok := resolver.Definition(name); ok || l.globals[name.Lexeme] {
+			continue
+		}
+		if _, ok := l.runtime.Globals.Values[name.Lexeme]; ok {
+			continue
+		}
+		l.warnToken(name, "Assignment to undeclared variable '"+name.Lexeme+"'.")
+	}
+}
+
+func (l *Linter) warnToken(token *Token, message string) {
+	l.warnings = append(l.warnings, NewWarning(token.Line, token.Column, message))
+}
+
+func (l *Linter) warnStmt(stmt Stmt, message string) {
+	line, column := 0, 0
+	if pos, ok := l.runtime.Positions[stmt]; ok {
+		line, column = pos.Line, pos.Column
+	}
+	l.warnings = append(l.warnings, NewWarning(line, column, message))
+}
@@ -0,0 +1,95 @@
+package golox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinter(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected []string
+		code     string
+	}{
+		{
+			name:     "no warning",
+			expected: []string{},
+			code:     "var a = 1;\nfun f(x) {\n  var y = x;\n  return y + a;\n}\nprint f(clock());",
+		},
+		{
+			name: "unused local and parameter",
+			expected: []string{
+				"1:10: Unused parameter 'y'.",
+				"2:7: Unused variable 'z'.",
+			},
+			code: "fun f(x, y) {\n  var z = x;\n  var _ignored = 1;\n}",
+		},
+		{
+			name: "unused local function and class",
+			expected: []string{
+				"2:7: Unused function 'g'.",
+				"3:9: Unused class 'C'.",
+			},
+			code: "{\n  fun g() {}\n  class C {}\n}",
+		},
+		{
+			name: "unreachable code",
+			expected: []string{
+				"4:3: Unreachable code.",
+			},
+			code: "fun f(x) {\n  if (x) { return 1; } else { return 2; }\n\n  print x;\n}",
+		},
+		{
+			name: "shadowing",
+			expected: []string{
+				"3:9: 'a' shadows a variable in an enclosing scope.",
+				"8:9: 'f' shadows a variable in an enclosing scope.",
+			},
+			code: "fun f(a) {\n  {\n    var a = 2;\n    print a;\n  }\n  return a;\n}\n{ class f {}\n  f();\n}",
+		},
+		{
+			name: "assignment to undeclared global",
+			expected: []string{
+				"3:3: Assignment to undeclared variable 'b'.",
+			},
+			code: "var a;\nfun f() {\n  b = 1;\n  a = 1;\n}",
+		},
+		{
+			name: "this outside of method",
+			expected: []string{
+				"1:7: Can't use 'this' outside of a class.",
+			},
+			code: "print this;\nclass A {\n  f() {\n    fun g() { return this; }\n    return g;\n  }\n}",
+		},
+		{
+			name:     "local named like native",
+			expected: []string{},
+			code:     "fun f(max) {\n  var map = max;\n  return map + clock();\n}\nprint f(1);",
+		},
+		{
+			name: "assignment is not use",
+			expected: []string{
+				"2:7: Unused variable 'x'.",
+			},
+			code: "{\n  var x = 1;\n  x = 2;\n}\nclock = 1;",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := golox.NewRuntime()
+			stmts := r.Parse(bytes.NewBufferString(tt.code))
+			assert.False(t, r.HadError)
+
+			actual := make([]string, 0)
+			for _, warning := range golox.NewLinter(r).Lint(stmts) {
+				actual = append(actual, warning.String())
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package lsp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/goropikari/golox"
+)
+
+// document is an analyzed Lox source file opened by the client
+type document struct {
+	uri          string
+	text         string
+	lines        []string
+	runtime      *golox.Runtime
+	tokens       golox.TokenList
+	statements   []golox.Stmt
+	resolver     *golox.Resolver
+	declarations map[*golox.Token]interface{}
+}
+
+func newDocument(uri, text string) *document {
+	r := golox.NewRuntime()
+	r.Stderr = ioutil.Discard
+
+	tokens := golox.NewScanner(r, bytes.NewBufferString(text)).ScanTokens()
+	statements, _ := golox.NewParser(r, tokens).Parse()
+
+	d := &document{
+		uri:          uri,
+		text:         text,
+		lines:        strings.Split(text, "\n"),
+		runtime:      r,
+		tokens:       tokens,
+		statements:   statements,
+		declarations: make(map[*golox.Token]interface{}),
+	}
+
+	// resolver expects syntactically valid statements
+	if !r.HadError {
+		d.resolver = golox.NewResolver(r, golox.NewInterpreter(r))
+		d.resolver.ResolveStmts(statements)
+		d.collectDeclarations(statements)
+	}
+
+	return d
+}
+
+// collectDeclarations records statements and patterns which declare names, including the ones nested in expressions
+func (d *document) collectDeclarations(stmts []golox.Stmt) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *golox.Block:
+			d.collectDeclarations(s.Statements)
+		case *golox.Class:
+			d.declarations[s.Name] = s
+			for _, method := range s.Methods {
+				d.declarations[method.Name] = method
+				d.collectDeclarations(method.Body)
+			}
+		case *golox.Expression:
+			d.collectExprDeclarations(s.Expression)
+		case *golox.Function:
+			d.declarations[s.Name] = s
+			d.collectDeclarations(s.Body)
+		case *golox.If:
+			d.collectExprDeclarations(s.Condition)
+			d.collectDeclarations([]golox.Stmt{s.ThenBranch})
+			if s.ElseBranch != nil {
+				d.collectDeclarations([]golox.Stmt{s.ElseBranch})
+			}
+		case *golox.Print:
+			d.collectExprDeclarations(s.Expression)
+		case *golox.Return:
+			d.collectExprDeclarations(s.Value)
+		case *golox.Switch:
+			d.collectExprDeclarations(s.Value)
+			for _, c := range s.Cases {
+				d.collectExprDeclarations(c.Values...)
+				d.collectDeclarations(c.Body)
+			}
+		case *golox.Var:
+			d.declarations[s.Name] = s
+			d.collectExprDeclarations(s.Initializer)
+		case *golox.While:
+			d.collectExprDeclarations(s.Condition)
+			d.collectDeclarations([]golox.Stmt{s.Body})
+		}
+	}
+}
+
+// collectExprDeclarations records declarations in lambda bodies and match arms
+func (d *document) collectExprDeclarations(exprs ...golox.Expr) {
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case *golox.Assign:
+			d.collectExprDeclarations(e.Value)
+		case *golox.Binary:
+			d.collectExprDeclarations(e.Left, e.Right)
+		case *golox.Call:
+			d.collectExprDeclarations(e.Callee)
+			d.collectExprDeclarations(e.Arguments...)
+		case *golox.CompoundAssign:
+			d.collectExprDeclarations(e.Target, e.Value)
+		case *golox.Conditional:
+			d.collectExprDeclarations(e.Condition, e.ThenBranch, e.ElseBranch)
+		case *golox.Get:
+			d.collectExprDeclarations(e.Object)
+		case *golox.Grouping:
+			d.collectExprDeclarations(e.Expression)
+		case *golox.Index:
+			d.collectExprDeclarations(e.Object, e.Index)
+		case *golox.Interpolation:
+			d.collectExprDeclarations(e.Parts...)
+		case *golox.Lambda:
+			d.collectDeclarations(e.Function.Body)
+		case *golox.List:
+			d.collectExprDeclarations(e.Elements...)
+		case *golox.Logical:
+			d.collectExprDeclarations(e.Left, e.Right)
+		case *golox.Match:
+			d.collectExprDeclarations(e.Value)
+			for _, arm := range e.Arms {
+				d.collectPatternDeclarations(arm.Pattern)
+				d.collectExprDeclarations(arm.Body)
+			}
+		case *golox.OptionalChain:
+			d.collectExprDeclarations(e.Expression)
+		case *golox.Set:
+			d.collectExprDeclarations(e.Object, e.Value)
+		case *golox.SetIndex:
+			d.collectExprDeclarations(e.Object, e.Index, e.Value)
+		case *golox.Unary:
+			d.collectExprDeclarations(e.Right)
+		}
+	}
+}
+
+// collectPatternDeclarations records names bound by the pattern
+func (d *document) collectPatternDeclarations(pattern golox.Pattern) {
+	switch p := pattern.(type) {
+	case *golox.BindingPattern:
+		d.declarations[p.Name] = p
+	case *golox.ListPattern:
+		for _, element := range p.Elements {
+			d.collectPatternDeclarations(element)
+		}
+	case *golox.ClassPattern:
+		for _, field := range p.Fields {
+			d.collectPatternDeclarations(field.Pattern)
+		}
+	case *golox.AlternativePattern:
+		for _, alternative := range p.Alternatives {
+			d.collectPatternDeclarations(alternative)
+		}
+	}
+}
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := make([]Diagnostic, 0)
+	for _, diag := range d.runtime.Diagnostics {
+		var rng Range
+		if diag.Column > 0 {
+			rng = d.tokenRange(diag.Line, diag.Column, diag.Lexeme)
+		} else {
+			rng = Range{Start: Position{Line: diag.Line - 1}, End: Position{Line: diag.Line}}
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    rng,
+			Severity: errorSeverity,
+			Source:   "golox",
+			Message:  diag.Message,
+		})
+	}
+	return diagnostics
+}
+
+// identifierAt returns the identifier token at the position
+func (d *document) identifierAt(pos Position) *golox.Token {
+	for _, token := range d.tokens {
+		if token.Type != golox.IdentifierTT || token.Line-1 != pos.Line {
+			continue
+		}
+		start := d.character(token.Line, token.Column)
+		end := start + utf16Length(token.Lexeme)
+		if start <= pos.Character && pos.Character <= end {
+			return token
+		}
+	}
+	return nil
+}
+
+// definition returns declaration of the identifier at the position
+func (d *document) definition(pos Position) *golox.Token {
+	token := d.identifierAt(pos)
+	if token == nil || d.resolver == nil {
+		return nil
+	}
+	decl, ok := d.resolver.Definition(token)
+	if !ok {
+		return nil
+	}
+	return decl
+}
+
+func (d *document) references(pos Position, includeDeclaration bool) []Location {
+	locations := make([]Location, 0)
+	decl := d.definition(pos)
+	if decl == nil {
+		return locations
+	}
+
+	for _, token := range d.resolver.References(decl) {
+		if token == decl && !includeDeclaration {
+			continue
+		}
+		locations = append(locations, d.location(token))
+	}
+	return locations
+}
+
+func (d *document) hover(pos Position) *Hover {
+	token := d.identifierAt(pos)
+	decl := d.definition(pos)
+	if decl == nil {
+		return nil
+	}
+
+	var signature string
+	switch s := d.declarations[decl].(type) {
+	case *golox.Function:
+		signature = "fun " + s.Name.Lexeme + "(" + joinParams(s.Params) + ")"
+	case *golox.Class:
+		signature = "class " + s.Name.Lexeme
+		if s.Superclass != nil {
+			signature += " < " + s.Superclass.Name.Lexeme
+		}
+		for _, method := range s.Methods {
+			if method.Name.Lexeme == "init" {
+				signature += "\ninit(" + joinParams(method.Params) + ")"
+			}
+		}
+	case *golox.Var:
+		signature = "var " + s.Name.Lexeme
+	case *golox.BindingPattern:
+		signature = "(binding) " + s.Name.Lexeme
+	default:
+		signature = "(parameter) " + decl.Lexeme
+	}
+
+	rng := d.location(token).Range
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```lox\n" + signature + "\n```"},
+		Range:    &rng,
+	}
+}
+
+func (d *document) symbols() []DocumentSymbol {
+	symbols := make([]DocumentSymbol, 0)
+	for _, stmt := range d.statements {
+		switch s := stmt.(type) {
+		case *golox.Class:
+			symbol := d.symbol(s, s.Name, classSymbol, "")
+			for _, method := range s.Methods {
+				child := d.symbol(method, method.Name, methodSymbol, "("+joinParams(method.Params)+")")
+				symbol.Children = append(symbol.Children, child)
+			}
+			symbols = append(symbols, symbol)
+		case *golox.Function:
+			symbols = append(symbols, d.symbol(s, s.Name, functionSymbol, "("+joinParams(s.Params)+")"))
+		case *golox.Var:
+			symbols = append(symbols, d.symbol(s, s.Name, variableSymbol, ""))
+		}
+	}
+	return symbols
+}
+
+func (d *document) symbol(stmt golox.Stmt, name *golox.Token, kind int, detail string) DocumentSymbol {
+	selection := d.location(name).Range
+	rng := selection
+	if pos, ok := d.runtime.Positions[stmt]; ok {
+		rng = Range{
+			Start: Position{Line: pos.Line - 1, Character: d.character(pos.Line, pos.Column)},
+			End:   Position{Line: pos.EndLine, Character: 0},
+		}
+	}
+
+	return DocumentSymbol{
+		Name:           name.Lexeme,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          rng,
+		SelectionRange: selection,
+	}
+}
+
+func (d *document) location(token *golox.Token) Location {
+	return Location{URI: d.uri, Range: d.tokenRange(token.Line, token.Column, token.Lexeme)}
+}
+
+// tokenRange returns range of the lexeme starting at 1-based line and column
+func (d *document) tokenRange(line, column int, lexeme string) Range {
+	start := d.character(line, column)
+	return Range{
+		Start: Position{Line: line - 1, Character: start},
+		End:   Position{Line: line - 1, Character: start + utf16Length(lexeme)},
+	}
+}
+
+// character converts 1-based column counted in runes into the offset in UTF-16 code units,
+// which is the default position encoding of LSP.
+func (d *document) character(line, column int) int {
+	var runes []rune
+	if 0 < line && line <= len(d.lines) {
+		runes = []rune(d.lines[line-1])
+	}
+	n := 0
+	for k := 0; k < column-1; k++ {
+		if k < len(runes) {
+			n += utf16.RuneLen(runes[k])
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// utf16Length returns the number of UTF-16 code units of s
+func utf16Length(s string) int {
+	n := 0
+	for _, c := range s {
+		n += utf16.RuneLen(c)
+	}
+	return n
+}
+
+func joinParams(params []*golox.Token) string {
+	names := make([]string, 0)
+	for _, param := range params {
+		names = append(names, param.Lexeme)
+	}
+	return strings.Join(names, ", ")
+}
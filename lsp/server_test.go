@@ -0,0 +1,298 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/golox/lsp"
+	"github.com/stretchr/testify/assert"
+)
+
+const uri = "file:///tmp/point.lox"
+
+const source = `class Point {
+  init(x, y) {
+    this.x = x;
+    this.y = y;
+  }
+  sum() { return this.x + this.y; }
+}
+fun add(a, b) {
+  var c = a + b;
+  return c;
+}
+var p = Point(1, 2);
+print add(p.sum(), 3);
+`
+
+// client is scripted JSON-RPC client
+type client struct {
+	t      *testing.T
+	writer io.Writer
+	reader *bufio.Reader
+	id     int
+	queue  []map[string]json.RawMessage
+}
+
+func (c *client) send(msg map[string]interface{}) {
+	msg["jsonrpc"] = "2.0"
+	body, err := json.Marshal(msg)
+	assert.NoError(c.t, err)
+	fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (c *client) receive() map[string]json.RawMessage {
+	length := 0
+	for {
+		line, err := c.reader.ReadString('\n')
+		assert.NoError(c.t, err)
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		length, _ = strconv.Atoi(strings.TrimPrefix(line, "Content-Length: "))
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(c.reader, body)
+	assert.NoError(c.t, err)
+
+	var msg map[string]json.RawMessage
+	assert.NoError(c.t, json.Unmarshal(body, &msg))
+	return msg
+}
+
+// request sends a request and returns result of the response
+func (c *client) request(method string, params interface{}) string {
+	c.id++
+	c.send(map[string]interface{}{"id": c.id, "method": method, "params": params})
+	for {
+		msg := c.receive()
+		if _, ok := msg["id"]; !ok {
+			c.queue = append(c.queue, msg)
+			continue
+		}
+		assert.Equal(c.t, strconv.Itoa(c.id), string(msg["id"]))
+		if e, ok := msg["error"]; ok {
+			return string(e)
+		}
+		return string(msg["result"])
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.send(map[string]interface{}{"method": method, "params": params})
+}
+
+// notification returns params of the next notification
+func (c *client) notification(method string) string {
+	var msg map[string]json.RawMessage
+	if len(c.queue) > 0 {
+		msg, c.queue = c.queue[0], c.queue[1:]
+	} else {
+		msg = c.receive()
+	}
+	assert.Equal(c.t, strconv.Quote(method), string(msg["method"]))
+	return string(msg["params"])
+}
+
+func position(line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": line, "character": character},
+	}
+}
+
+// serve starts server and returns client connected to it and channel of the result of the server
+func serve(t *testing.T) (*client, chan error) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server := lsp.NewServer(serverReader, serverWriter)
+	done := make(chan error)
+	go func() {
+		done <- server.Serve()
+		serverWriter.Close()
+	}()
+
+	return &client{t: t, writer: clientWriter, reader: bufio.NewReader(clientReader)}, done
+}
+
+func TestServer(t *testing.T) {
+	c, done := serve(t)
+
+	result := c.request("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}})
+	assert.Contains(t, result, `"definitionProvider":true`)
+	c.notify("initialized", map[string]interface{}{})
+
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "lox", "version": 1, "text": source},
+	})
+	assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[]}`, c.notification("textDocument/publishDiagnostics"))
+
+	t.Run("definition of function", func(t *testing.T) {
+		result := c.request("textDocument/definition", position(12, 7))
+		assert.JSONEq(t, `{"uri":"`+uri+`","range":{"start":{"line":7,"character":4},"end":{"line":7,"character":7}}}`, result)
+	})
+
+	t.Run("definition of local variable", func(t *testing.T) {
+		result := c.request("textDocument/definition", position(9, 9))
+		assert.JSONEq(t, `{"uri":"`+uri+`","range":{"start":{"line":8,"character":6},"end":{"line":8,"character":7}}}`, result)
+	})
+
+	t.Run("definition of property is unknown", func(t *testing.T) {
+		assert.Equal(t, "null", c.request("textDocument/definition", position(12, 13)))
+	})
+
+	t.Run("references of parameter", func(t *testing.T) {
+		params := position(7, 8)
+		params["context"] = map[string]bool{"includeDeclaration": true}
+		result := c.request("textDocument/references", params)
+		assert.JSONEq(t, `[
+			{"uri":"`+uri+`","range":{"start":{"line":7,"character":8},"end":{"line":7,"character":9}}},
+			{"uri":"`+uri+`","range":{"start":{"line":8,"character":10},"end":{"line":8,"character":11}}}
+		]`, result)
+	})
+
+	t.Run("references of class without declaration", func(t *testing.T) {
+		params := position(0, 7)
+		params["context"] = map[string]bool{"includeDeclaration": false}
+		result := c.request("textDocument/references", params)
+		assert.JSONEq(t, `[
+			{"uri":"`+uri+`","range":{"start":{"line":11,"character":8},"end":{"line":11,"character":13}}}
+		]`, result)
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		result := c.request("textDocument/hover", position(12, 7))
+		assert.JSONEq(t, `{"contents":{"kind":"markdown","value":"`+"```lox\\nfun add(a, b)\\n```"+`"},"range":{"start":{"line":12,"character":6},"end":{"line":12,"character":9}}}`, result)
+
+		result = c.request("textDocument/hover", position(11, 9))
+		assert.Contains(t, result, "class Point\\ninit(x, y)")
+	})
+
+	t.Run("document symbols", func(t *testing.T) {
+		result := c.request("textDocument/documentSymbol", map[string]interface{}{"textDocument": map[string]string{"uri": uri}})
+		var symbols []struct {
+			Name     string
+			Kind     int
+			Children []struct {
+				Name string
+				Kind int
+			}
+		}
+		assert.NoError(t, json.Unmarshal([]byte(result), &symbols))
+		assert.Equal(t, 3, len(symbols))
+		assert.Equal(t, "Point", symbols[0].Name)
+		assert.Equal(t, 5, symbols[0].Kind)
+		assert.Equal(t, "init", symbols[0].Children[0].Name)
+		assert.Equal(t, "sum", symbols[0].Children[1].Name)
+		assert.Equal(t, 6, symbols[0].Children[1].Kind)
+		assert.Equal(t, "add", symbols[1].Name)
+		assert.Equal(t, 12, symbols[1].Kind)
+		assert.Equal(t, "p", symbols[2].Name)
+	})
+
+	t.Run("diagnostics on change", func(t *testing.T) {
+		c.notify("textDocument/didChange", map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+			"contentChanges": []map[string]string{{"text": "var = 1;"}},
+		})
+		assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[
+			{"range":{"start":{"line":0,"character":4},"end":{"line":0,"character":5}},"severity":1,"source":"golox","message":"Expect variable name."}
+		]}`, c.notification("textDocument/publishDiagnostics"))
+
+		c.notify("textDocument/didChange", map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": 3},
+			"contentChanges": []map[string]string{{"text": "return 1;"}},
+		})
+		assert.Contains(t, c.notification("textDocument/publishDiagnostics"), "Can't return from top-level code.")
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		assert.Contains(t, c.request("workspace/unknown", nil), `"code":-32601`)
+	})
+
+	assert.Equal(t, "null", c.request("shutdown", nil))
+	c.notify("exit", nil)
+	assert.NoError(t, <-done)
+}
+
+const nestedSource = `fun f(v) {
+  switch (v) {
+    case 1:
+      var s = 1;
+      print s;
+  }
+  var g = x => {
+    var inner = x;
+    return inner;
+  };
+  return match (v) {
+    [a, _] => a,
+    _ => nil,
+  };
+}
+`
+
+func TestServer_HoverNested(t *testing.T) {
+	c, done := serve(t)
+	c.request("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}})
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "lox", "version": 1, "text": nestedSource},
+	})
+	assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[]}`, c.notification("textDocument/publishDiagnostics"))
+
+	var tests = []struct {
+		name      string
+		expected  string
+		line      int
+		character int
+	}{
+		{name: "variable in case body", expected: "var s", line: 4, character: 12},
+		{name: "variable in lambda body", expected: "var inner", line: 8, character: 11},
+		{name: "lambda parameter", expected: "(parameter) x", line: 7, character: 16},
+		{name: "binding of match arm", expected: "(binding) a", line: 11, character: 14},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result := c.request("textDocument/hover", position(tt.line, tt.character))
+			assert.Contains(t, result, `"value":"`+"```lox\\n"+tt.expected+"\\n```"+`"`)
+		})
+	}
+
+	assert.Equal(t, "null", c.request("shutdown", nil))
+	c.notify("exit", nil)
+	assert.NoError(t, <-done)
+}
+
+// positions are counted in UTF-16 code units, so an emoji takes two characters
+func TestServer_UTF16(t *testing.T) {
+	c, done := serve(t)
+	c.request("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}})
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "lox", "version": 1, "text": "var s = \"😀\"; var x = 1;\nprint s + x;\n"},
+	})
+	assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[]}`, c.notification("textDocument/publishDiagnostics"))
+
+	result := c.request("textDocument/definition", position(1, 10))
+	assert.JSONEq(t, `{"uri":"`+uri+`","range":{"start":{"line":0,"character":18},"end":{"line":0,"character":19}}}`, result)
+	assert.Equal(t, "null", c.request("textDocument/definition", position(0, 17)))
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "print \"😀\" +;"}},
+	})
+	assert.JSONEq(t, `{"uri":"`+uri+`","diagnostics":[
+		{"range":{"start":{"line":0,"character":12},"end":{"line":0,"character":13}},"severity":1,"source":"golox","message":"Expect expression."}
+	]}`, c.notification("textDocument/publishDiagnostics"))
+
+	assert.Equal(t, "null", c.request("shutdown", nil))
+	c.notify("exit", nil)
+	assert.NoError(t, <-done)
+}
@@ -1,18 +1,80 @@
 package golox
 
-// NativeCallable is interface to call native function
+import "fmt"
+
+// NativeCallable is interface to call native function.
+// The context gives access to the interpreter and the call site.
 type NativeCallable interface {
-	Call([]interface{}) (interface{}, error)
+	Call(ctx *NativeContext, arguments []interface{}) (interface{}, error)
 	Arity() int
 }
 
+// SimpleNativeCallable is interface of native function which only needs its arguments, such as natives of native_function package
+type SimpleNativeCallable interface {
+	Call(arguments []interface{}) (interface{}, error)
+	Arity() int
+}
+
+// NativeContext is handle which native functions use to interact with the interpreter
+type NativeContext struct {
+	Interpreter *Interpreter
+	// Token is closing parenthesis of the call. It's nil when the native is called from Go, such as a callback of map.
+	Token *Token
+}
+
+// NewNativeContext is constructor of NativeContext
+func NewNativeContext(interpreter *Interpreter, token *Token) *NativeContext {
+	return &NativeContext{
+		Interpreter: interpreter,
+		Token:       token,
+	}
+}
+
+// Error returns runtime error reported at the call site
+func (ctx *NativeContext) Error(format string, a ...interface{}) error {
+	return RuntimeError.New(ctx.Token, fmt.Sprintf(format, a...))
+}
+
+// Call calls a Lox function or class with the arguments. Calling a class creates its instance.
+func (ctx *NativeContext) Call(callee interface{}, arguments ...interface{}) (interface{}, error) {
+	function, ok := callee.(GoLoxCallable)
+	if !ok {
+		return nil, ctx.Error("Can only call functions and classes.")
+	}
+	if len(arguments) != function.Arity() {
+		return nil, ctx.Error("Expected %d arguments but got %d.", function.Arity(), len(arguments))
+	}
+	return function.Call(ctx.Interpreter, arguments)
+}
+
+// Global returns value of the global variable
+func (ctx *NativeContext) Global(name string) (interface{}, error) {
+	value, ok := ctx.Interpreter.Runtime.Globals.Values[name]
+	if !ok {
+		return nil, ctx.Error("Undefined variable '%s'.", name)
+	}
+	return value, nil
+}
+
+// IsTruthy reports whether value is truthy in Lox
+func (ctx *NativeContext) IsTruthy(value interface{}) bool {
+	return ctx.Interpreter.isTruthy(value)
+}
+
 // NativeFunction is struct for native function
 type NativeFunction struct {
 	Function NativeCallable
 }
 
 // NewNativeFunction is constructor of NativeFunction
-func NewNativeFunction(function NativeCallable) GoLoxCallable {
+func NewNativeFunction(function SimpleNativeCallable) GoLoxCallable {
+	return &NativeFunction{
+		Function: &simpleNativeCallable{function: function},
+	}
+}
+
+// NewContextNativeFunction is constructor of NativeFunction whose callable receives NativeContext
+func NewContextNativeFunction(function NativeCallable) GoLoxCallable {
 	return &NativeFunction{
 		Function: function,
 	}
@@ -20,10 +82,32 @@ func NewNativeFunction(function NativeCallable) GoLoxCallable {
 
 // Call calls native function
 func (nf *NativeFunction) Call(i *Interpreter, args []interface{}) (interface{}, error) {
-	return nf.Function.Call(args)
+	return nf.Function.Call(NewNativeContext(i, nil), args)
+}
+
+// CallAt calls native function from the call whose closing parenthesis is token
+func (nf *NativeFunction) CallAt(i *Interpreter, token *Token, args []interface{}) (interface{}, error) {
+	return nf.Function.Call(NewNativeContext(i, token), args)
 }
 
 // Arity returns arity of native function
 func (nf *NativeFunction) Arity() int {
 	return nf.Function.Arity()
 }
+
+func (nf *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+// simpleNativeCallable adapts SimpleNativeCallable to NativeCallable
+type simpleNativeCallable struct {
+	function SimpleNativeCallable
+}
+
+func (snc *simpleNativeCallable) Call(ctx *NativeContext, arguments []interface{}) (interface{}, error) {
+	return snc.function.Call(arguments)
+}
+
+func (snc *simpleNativeCallable) Arity() int {
+	return snc.function.Arity()
+}
@@ -0,0 +1,178 @@
+package native_function
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// MathFunc is a math function whose arguments are all numbers
+type MathFunc struct {
+	name     string
+	arity    int
+	function func(arguments []float64) float64
+}
+
+// NewSqrtFunc returns sqrt(x)
+func NewSqrtFunc() *MathFunc { return newUnaryMathFunc("sqrt", math.Sqrt) }
+
+// NewPowFunc returns pow(x, y)
+func NewPowFunc() *MathFunc { return newBinaryMathFunc("pow", math.Pow) }
+
+// NewFloorFunc returns floor(x)
+func NewFloorFunc() *MathFunc { return newUnaryMathFunc("floor", math.Floor) }
+
+// NewCeilFunc returns ceil(x)
+func NewCeilFunc() *MathFunc { return newUnaryMathFunc("ceil", math.Ceil) }
+
+// NewRoundFunc returns round(x) which rounds half away from zero
+func NewRoundFunc() *MathFunc { return newUnaryMathFunc("round", math.Round) }
+
+// NewAbsFunc returns abs(x)
+func NewAbsFunc() *MathFunc { return newUnaryMathFunc("abs", math.Abs) }
+
+// NewMinFunc returns min(x, y)
+func NewMinFunc() *MathFunc { return newBinaryMathFunc("min", math.Min) }
+
+// NewMaxFunc returns max(x, y)
+func NewMaxFunc() *MathFunc { return newBinaryMathFunc("max", math.Max) }
+
+// NewSinFunc returns sin(x)
+func NewSinFunc() *MathFunc { return newUnaryMathFunc("sin", math.Sin) }
+
+// NewCosFunc returns cos(x)
+func NewCosFunc() *MathFunc { return newUnaryMathFunc("cos", math.Cos) }
+
+// NewTanFunc returns tan(x)
+func NewTanFunc() *MathFunc { return newUnaryMathFunc("tan", math.Tan) }
+
+// NewAsinFunc returns asin(x)
+func NewAsinFunc() *MathFunc { return newUnaryMathFunc("asin", math.Asin) }
+
+// NewAcosFunc returns acos(x)
+func NewAcosFunc() *MathFunc { return newUnaryMathFunc("acos", math.Acos) }
+
+// NewAtanFunc returns atan(x)
+func NewAtanFunc() *MathFunc { return newUnaryMathFunc("atan", math.Atan) }
+
+// NewAtan2Func returns atan2(y, x)
+func NewAtan2Func() *MathFunc { return newBinaryMathFunc("atan2", math.Atan2) }
+
+// NewLogFunc returns log(x), the natural logarithm
+func NewLogFunc() *MathFunc { return newUnaryMathFunc("log", math.Log) }
+
+// NewExpFunc returns exp(x)
+func NewExpFunc() *MathFunc { return newUnaryMathFunc("exp", math.Exp) }
+
+func newUnaryMathFunc(name string, f func(float64) float64) *MathFunc {
+	return &MathFunc{
+		name:     name,
+		arity:    1,
+		function: func(arguments []float64) float64 { return f(arguments[0]) },
+	}
+}
+
+func newBinaryMathFunc(name string, f func(float64, float64) float64) *MathFunc {
+	return &MathFunc{
+		name:     name,
+		arity:    2,
+		function: func(arguments []float64) float64 { return f(arguments[0], arguments[1]) },
+	}
+}
+
+// Arity returns the number of parameters
+func (mf *MathFunc) Arity() int {
+	return mf.arity
+}
+
+// Call applies the function to the arguments
+func (mf *MathFunc) Call(arguments []interface{}) (interface{}, error) {
+	numbers := make([]float64, 0, len(arguments))
+	for k, argument := range arguments {
+		n, ok := argument.(float64)
+		if !ok {
+			return nil, fmt.Errorf("Argument %d of %s must be a number but got %s of type %s.", k+1, mf.name, Inspect(argument), TypeOf(argument))
+		}
+		numbers = append(numbers, n)
+	}
+	return mf.function(numbers), nil
+}
+
+func (mf *MathFunc) String() string {
+	return "<native fn>"
+}
+
+// random()
+// ex. random() // 0.6046602879796196
+
+// RandomFunc returns a pseudo-random number in [0, 1)
+type RandomFunc struct {
+	rand *rand.Rand
+}
+
+// NewRandomFunc is constructor of RandomFunc. Numbers are drawn from r so that the host can fix the seed.
+func NewRandomFunc(r *rand.Rand) *RandomFunc {
+	return &RandomFunc{rand: r}
+}
+
+// Arity returns 0
+func (rf *RandomFunc) Arity() int {
+	return 0
+}
+
+// Call returns a pseudo-random number in [0, 1)
+func (rf *RandomFunc) Call(arguments []interface{}) (interface{}, error) {
+	return rf.rand.Float64(), nil
+}
+
+func (rf *RandomFunc) String() string {
+	return "<native fn>"
+}
+
+// randomInt(min, max)
+// ex. randomInt(1, 6) // dice
+
+// RandomIntFunc returns a pseudo-random integer in [min, max]
+type RandomIntFunc struct {
+	rand *rand.Rand
+}
+
+// NewRandomIntFunc is constructor of RandomIntFunc. Numbers are drawn from r so that the host can fix the seed.
+func NewRandomIntFunc(r *rand.Rand) *RandomIntFunc {
+	return &RandomIntFunc{rand: r}
+}
+
+// Arity returns 2
+func (rf *RandomIntFunc) Arity() int {
+	return 2
+}
+
+// Call returns a pseudo-random integer in [min, max]
+func (rf *RandomIntFunc) Call(arguments []interface{}) (interface{}, error) {
+	min, ok := arguments[0].(float64)
+	if !ok || min != math.Trunc(min) || math.IsInf(min, 0) {
+		return nil, fmt.Errorf("Argument 1 of randomInt must be an integer but got %s.", Inspect(arguments[0]))
+	}
+	max, ok := arguments[1].(float64)
+	if !ok || max != math.Trunc(max) || math.IsInf(max, 0) {
+		return nil, fmt.Errorf("Argument 2 of randomInt must be an integer but got %s.", Inspect(arguments[1]))
+	}
+	if min > max {
+		return nil, fmt.Errorf("Range of randomInt is empty: %s > %s.", FormatNumber(min), FormatNumber(max))
+	}
+	for k, n := range []float64{min, max} {
+		if n < math.MinInt64 || n >= math.MaxInt64 {
+			return nil, fmt.Errorf("Argument %d of randomInt is out of range: %s.", k+1, FormatNumber(n))
+		}
+	}
+	// the number of candidates must fit in int64
+	span := int64(max) - int64(min)
+	if span < 0 || span == math.MaxInt64 {
+		return nil, fmt.Errorf("Argument 2 of randomInt is too far from argument 1: range [%s, %s] has more than 2^63 - 1 integers.", FormatNumber(min), FormatNumber(max))
+	}
+	return min + float64(rf.rand.Int63n(span+1)), nil
+}
+
+func (rf *RandomIntFunc) String() string {
+	return "<native fn>"
+}
@@ -0,0 +1,115 @@
+package native_function
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxFractionDigits is the maximum digits of toFixed
+const maxFractionDigits = 100
+
+// FormatNumber returns string representation of number in the same way as jlox.
+// Integral numbers are printed without fraction part, and others are printed in the shortest
+// representation which round-trips. Numbers too small to be printed in decimal use exponent notation
+// such as 1.0E-7 as Java's Double.toString does.
+func FormatNumber(n float64) string {
+	switch {
+	case math.IsNaN(n):
+		return "NaN"
+	case math.IsInf(n, 1):
+		return "Infinity"
+	case math.IsInf(n, -1):
+		return "-Infinity"
+	case n != 0 && math.Abs(n) < 1e-6:
+		return formatExponent(n)
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// formatExponent returns n in the form of mantissa and exponent like 1.5E-7.
+// Unlike Go, the mantissa always has a fraction part and the exponent isn't zero-padded.
+func formatExponent(n float64) string {
+	text := strconv.FormatFloat(n, 'e', -1, 64)
+	k := strings.IndexByte(text, 'e')
+	mantissa, exponent := text[:k], text[k+1:]
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	e, _ := strconv.Atoi(exponent)
+	return mantissa + "E" + strconv.Itoa(e)
+}
+
+// Stringify returns string representation of Lox value
+func Stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return FormatNumber(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// str(value)
+// ex. str(1) + "st"
+
+// StrFunc converts a value into string
+type StrFunc struct{}
+
+// NewStrFunc is constructor of StrFunc
+func NewStrFunc() *StrFunc {
+	return &StrFunc{}
+}
+
+// Arity returns 1
+func (sf *StrFunc) Arity() int {
+	return 1
+}
+
+// Call returns string representation of the argument as print statement shows it
+func (sf *StrFunc) Call(arguments []interface{}) (interface{}, error) {
+	return Stringify(arguments[0]), nil
+}
+
+func (sf *StrFunc) String() string {
+	return "<native fn>"
+}
+
+// toFixed(number, digits)
+// ex. toFixed(3.14159, 2) // "3.14"
+
+// ToFixedFunc formats a number with fixed digits after the decimal point
+type ToFixedFunc struct{}
+
+// NewToFixedFunc is constructor of ToFixedFunc
+func NewToFixedFunc() *ToFixedFunc {
+	return &ToFixedFunc{}
+}
+
+// Arity returns 2
+func (tf *ToFixedFunc) Arity() int {
+	return 2
+}
+
+// Call returns the number rounded to given digits as string
+func (tf *ToFixedFunc) Call(arguments []interface{}) (interface{}, error) {
+	n, ok := arguments[0].(float64)
+	if !ok {
+		return nil, errors.New("First argument of toFixed must be a number.")
+	}
+	digits, ok := arguments[1].(float64)
+	if !ok || digits != math.Trunc(digits) || digits < 0 || digits > maxFractionDigits {
+		return nil, fmt.Errorf("Digits of toFixed must be an integer between 0 and %d.", maxFractionDigits)
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return FormatNumber(n), nil
+	}
+	return strconv.FormatFloat(n, 'f', int(digits), 64), nil
+}
+
+func (tf *ToFixedFunc) String() string {
+	return "<native fn>"
+}
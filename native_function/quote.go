@@ -0,0 +1,62 @@
+package native_function
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Inspect returns representation of value which distinguishes strings from other values.
+// Strings are quoted in the same way as Lox source code.
+func Inspect(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return Quote(s)
+	}
+	return Stringify(value)
+}
+
+// Quote returns Lox string literal which represents s, such as "a\tb"
+func Quote(s string) string {
+	return "\"" + Escape(s) + "\""
+}
+
+// Escape returns content of Lox string literal which represents s
+func Escape(s string) string {
+	var buf strings.Builder
+	runes := []rune(s)
+	for k, c := range runes {
+		switch c {
+		case '\\':
+			buf.WriteString("\\\\")
+		case '"':
+			buf.WriteString("\\\"")
+		case '$':
+			// keep ${ from being interpolation
+			if k+1 < len(runes) && runes[k+1] == '{' {
+				buf.WriteString("\\")
+			}
+			buf.WriteRune(c)
+		case '\n':
+			buf.WriteString("\\n")
+		case '\r':
+			buf.WriteString("\\r")
+		case '\b':
+			buf.WriteString("\\b")
+		case '\t':
+			buf.WriteString("\\t")
+		case '\f':
+			buf.WriteString("\\f")
+		case '\v':
+			buf.WriteString("\\v")
+		case 0:
+			buf.WriteString("\\0")
+		default:
+			if unicode.IsControl(c) {
+				fmt.Fprintf(&buf, "\\u{%x}", c)
+				break
+			}
+			buf.WriteRune(c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,174 @@
+package native_function
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Typed is implemented by Lox values which aren't functions nor primitives, such as classes and instances
+type Typed interface {
+	TypeName() string
+}
+
+// Instance is implemented by instances of Lox classes
+type Instance interface {
+	// IsInstanceOf reports whether the instance belongs to class or its subclasses
+	IsInstanceOf(class interface{}) bool
+}
+
+// callable is implemented by functions, natives and classes
+type callable interface {
+	Arity() int
+}
+
+// TypeOf returns type name of Lox value
+func TypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case Typed:
+		return v.TypeName()
+	case callable:
+		return "function"
+	}
+	return "unknown"
+}
+
+// num(value)
+// ex. num("42") + 1
+
+// NumFunc converts a string into number
+type NumFunc struct {
+	parse func(text string) (float64, error)
+}
+
+// NewNumFunc is constructor of NumFunc. parse is parser of number literals so that num accepts the same syntax as the source code, such as 0xFF and 1_000.
+func NewNumFunc(parse func(text string) (float64, error)) *NumFunc {
+	return &NumFunc{parse: parse}
+}
+
+// Arity returns 1
+func (nf *NumFunc) Arity() int {
+	return 1
+}
+
+// Call returns number which the argument represents. A leading sign is allowed.
+func (nf *NumFunc) Call(arguments []interface{}) (interface{}, error) {
+	switch v := arguments[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		text, sign := strings.TrimSpace(v), 1.0
+		if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+			if text[0] == '-' {
+				sign = -1
+			}
+			text = text[1:]
+		}
+		n, err := nf.parse(text)
+		if text == "" || strings.ContainsAny(text[:1], "+-") || err != nil {
+			return nil, fmt.Errorf("Can't convert %s to number.", Inspect(v))
+		}
+		return sign * n, nil
+	}
+	return nil, fmt.Errorf("Can't convert %s of type %s to number.", Inspect(arguments[0]), TypeOf(arguments[0]))
+}
+
+func (nf *NumFunc) String() string {
+	return "<native fn>"
+}
+
+// bool(value)
+// ex. bool(nil) // false
+
+// BoolFunc converts a value into bool by truthiness
+type BoolFunc struct{}
+
+// NewBoolFunc is constructor of BoolFunc
+func NewBoolFunc() *BoolFunc {
+	return &BoolFunc{}
+}
+
+// Arity returns 1
+func (bf *BoolFunc) Arity() int {
+	return 1
+}
+
+// Call returns false if the argument is nil or false, otherwise true
+func (bf *BoolFunc) Call(arguments []interface{}) (interface{}, error) {
+	switch v := arguments[0].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return true, nil
+}
+
+func (bf *BoolFunc) String() string {
+	return "<native fn>"
+}
+
+// type(value)
+// ex. type(1) // "number"
+
+// TypeFunc returns type name of a value
+type TypeFunc struct{}
+
+// NewTypeFunc is constructor of TypeFunc
+func NewTypeFunc() *TypeFunc {
+	return &TypeFunc{}
+}
+
+// Arity returns 1
+func (tf *TypeFunc) Arity() int {
+	return 1
+}
+
+// Call returns one of "nil", "number", "string", "bool", "function", "class", "instance" and "list"
+func (tf *TypeFunc) Call(arguments []interface{}) (interface{}, error) {
+	return TypeOf(arguments[0]), nil
+}
+
+func (tf *TypeFunc) String() string {
+	return "<native fn>"
+}
+
+// instanceOf(object, class)
+// ex. instanceOf(Dog(), Animal)
+
+// InstanceOfFunc checks class of an instance
+type InstanceOfFunc struct{}
+
+// NewInstanceOfFunc is constructor of InstanceOfFunc
+func NewInstanceOfFunc() *InstanceOfFunc {
+	return &InstanceOfFunc{}
+}
+
+// Arity returns 2
+func (iof *InstanceOfFunc) Arity() int {
+	return 2
+}
+
+// Call reports whether object is an instance of class or its subclasses
+func (iof *InstanceOfFunc) Call(arguments []interface{}) (interface{}, error) {
+	object, class := arguments[0], arguments[1]
+	if TypeOf(class) != "class" {
+		return nil, fmt.Errorf("Second argument of instanceOf must be a class but got %s of type %s.", Inspect(class), TypeOf(class))
+	}
+	instance, ok := object.(Instance)
+	if !ok {
+		return false, nil
+	}
+	return instance.IsInstanceOf(class), nil
+}
+
+func (iof *InstanceOfFunc) String() string {
+	return "<native fn>"
+}
@@ -0,0 +1,313 @@
+package golox_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/goropikari/golox/native_function"
+	"github.com/stretchr/testify/assert"
+)
+
+// runScript runs source and returns stdout and stderr
+func runScript(source string) (string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stdout = stdout
+	r.Stderr = stderr
+	r.Run(bytes.NewBufferString(source))
+	return stdout.String(), stderr.String()
+}
+
+func TestFormatNumber(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    float64
+	}{
+		{name: "integer", expected: "3", given: 3},
+		{name: "negative zero", expected: "-0", given: math.Copysign(0, -1)},
+		{name: "million", expected: "1000000", given: 1e6},
+		{name: "large integer", expected: "1000000000000000000000", given: 1e21},
+		{name: "fraction", expected: "0.1", given: 0.1},
+		{name: "shortest round-trip", expected: "0.30000000000000004", given: 0.30000000000000004},
+		{name: "small fraction", expected: "0.000001", given: 1e-6},
+		{name: "tiny fraction", expected: "1.0E-7", given: 1e-7},
+		{name: "tiny fraction with digits", expected: "-1.25E-10", given: -1.25e-10},
+		{name: "smallest number", expected: "5.0E-324", given: 5e-324},
+		{name: "infinity", expected: "Infinity", given: math.Inf(1)},
+		{name: "negative infinity", expected: "-Infinity", given: math.Inf(-1)},
+		{name: "NaN", expected: "NaN", given: math.NaN()},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, native_function.FormatNumber(tt.given))
+		})
+	}
+}
+
+func TestNumberNatives(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "print large number", expected: "1000000\n", given: "print 1000 * 1000;"},
+		{name: "print division by zero", expected: "Infinity\n", given: "print 1 / 0;"},
+		{name: "str of number", expected: "3rd\n", given: `print str(3) + "rd";`},
+		{name: "str of nil", expected: "nil\n", given: `print str(nil) + "";`},
+		{name: "str of bool", expected: "true\n", given: `print str(true) + "";`},
+		{name: "str of string", expected: "s\n", given: `print str("s");`},
+		{name: "str of class", expected: "A instance\n", given: `class A {} print str(A());`},
+		{name: "toFixed", expected: "3.14\n", given: "print toFixed(3.14159, 2);"},
+		{name: "toFixed rounds", expected: "3\n", given: "print toFixed(2.5001, 0);"},
+		{name: "toFixed pads", expected: "1.500\n", given: "print toFixed(1.5, 3);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestNumberNatives_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "toFixed of string", expected: "First argument of toFixed must be a number.\n[line 1]\n", given: `toFixed("1", 2);`},
+		{name: "toFixed with fraction digits", expected: "Digits of toFixed must be an integer between 0 and 100.\n[line 1]\n", given: "toFixed(1, 0.5);"},
+		{name: "toFixed with negative digits", expected: "Digits of toFixed must be an integer between 0 and 100.\n[line 1]\n", given: "toFixed(1, -1);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
+
+func TestTypeNatives(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "num of string", expected: "43\n", given: `print num(" 42 ") + 1;`},
+		{name: "num of fraction", expected: "-0.5\n", given: `print num("-5e-1");`},
+		{name: "num of number", expected: "3\n", given: "print num(3);"},
+		{name: "num of literal syntax", expected: "16\n-3\n1000\n", given: `print num("0x10"); print num("-0b11"); print num("1_000");`},
+		{name: "bool of nil", expected: "false\n", given: "print bool(nil);"},
+		{name: "bool of zero", expected: "true\n", given: "print bool(0);"},
+		{name: "bool of empty string", expected: "true\n", given: `print bool("");`},
+		{
+			name:     "type",
+			expected: "nil\nnumber\nstring\nbool\nfunction\nfunction\nfunction\nclass\ninstance\nlist\n",
+			given: `class A {}
+fun f() {}
+print type(nil);
+print type(1);
+print type("a");
+print type(true);
+print type(f);
+print type(clock);
+print type("a".len);
+print type(A);
+print type(A());
+print type([1]);`,
+		},
+		{
+			name:     "instanceOf",
+			expected: "true\ntrue\nfalse\nfalse\n",
+			given: `class A {}
+class B < A {}
+class C {}
+print instanceOf(B(), B);
+print instanceOf(B(), A);
+print instanceOf(A(), B);
+print instanceOf(1, C);`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestTypeNatives_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "num of non numeric string", expected: "Can't convert \"abc\" to number.\n[line 1]\n", given: `num("abc");`},
+		{name: "num of double sign", expected: "Can't convert \"--5\" to number.\n[line 1]\n", given: `num("--5");`},
+		{name: "num of empty string", expected: "Can't convert \"\" to number.\n[line 1]\n", given: `num("");`},
+		{name: "num quotes as Lox", expected: "Can't convert \"\\u{1b}\\${x}\" to number.\n[line 1]\n", given: `num("\x1b\${x}");`},
+		{name: "num of inf", expected: "Can't convert \"inf\" to number.\n[line 1]\n", given: `num("inf");`},
+		{name: "num of Infinity", expected: "Can't convert \"-Infinity\" to number.\n[line 1]\n", given: `num("-Infinity");`},
+		{name: "num of NaN", expected: "Can't convert \"NaN\" to number.\n[line 1]\n", given: `num("NaN");`},
+		{name: "num of leading dot", expected: "Can't convert \".5\" to number.\n[line 1]\n", given: `num(".5");`},
+		{name: "num of trailing dot", expected: "Can't convert \"1.\" to number.\n[line 1]\n", given: `num("1.");`},
+		{name: "num of exponent without digits", expected: "Can't convert \"1e\" to number.\n[line 1]\n", given: `num("1e");`},
+		{name: "num of hex float", expected: "Can't convert \"0x1p-2\" to number.\n[line 1]\n", given: `num("0x1p-2");`},
+		{name: "num of bool", expected: "Can't convert true of type bool to number.\n[line 1]\n", given: "num(true);"},
+		{name: "instanceOf non class", expected: "Second argument of instanceOf must be a class but got 1 of type number.\n[line 1]\n", given: "instanceOf(1, 1);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
+
+func TestMathNatives(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "sqrt", expected: "3\n", given: "print sqrt(9);"},
+		{name: "pow", expected: "1024\n", given: "print pow(2, 10);"},
+		{name: "floor and ceil", expected: "-2\n2\n", given: "print floor(-1.5); print ceil(1.2);"},
+		{name: "round half away from zero", expected: "3\n-3\n", given: "print round(2.5); print round(-2.5);"},
+		{name: "abs", expected: "4\n", given: "print abs(-4);"},
+		{name: "min and max", expected: "1\n2\n", given: "print min(1, 2); print max(1, 2);"},
+		{name: "trig", expected: "0\n1\n3.141592653589793\n", given: "print sin(0); print cos(0); print 2 * atan2(1, 0);"},
+		{name: "log and exp", expected: "1\n1\n", given: "print log(E); print exp(0);"},
+		{name: "constants", expected: "3.141592653589793\nInfinity\nNaN\n", given: "print PI; print INF; print NAN;"},
+		{name: "random", expected: "true\n", given: "var r = random(); print 0 <= r and r < 1;"},
+		{name: "randomInt", expected: "true\n", given: "var r = randomInt(1, 6); print r == floor(r) and 1 <= r and r <= 6;"},
+		{name: "randomInt of single value", expected: "3\n", given: "print randomInt(3, 3);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestMathNatives_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "sqrt of string", expected: "Argument 1 of sqrt must be a number but got \"4\" of type string.\n[line 1]\n", given: `sqrt("4");`},
+		{name: "pow of nil", expected: "Argument 2 of pow must be a number but got nil of type nil.\n[line 1]\n", given: "pow(2, nil);"},
+		{name: "randomInt of fraction", expected: "Argument 1 of randomInt must be an integer but got 0.5.\n[line 1]\n", given: "randomInt(0.5, 1);"},
+		{name: "randomInt of empty range", expected: "Range of randomInt is empty: 2 > 1.\n[line 1]\n", given: "randomInt(2, 1);"},
+		{name: "randomInt beyond int64", expected: "Argument 1 of randomInt is out of range: -10000000000000000000.\n[line 1]\n", given: "randomInt(-1e19, 1e19);"},
+		{name: "randomInt of too wide range", expected: "Argument 2 of randomInt is too far from argument 1: range [-5000000000000000000, 5000000000000000000] has more than 2^63 - 1 integers.\n[line 1]\n", given: "randomInt(-5e18, 5e18);"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
+
+func TestRuntime_Seed(t *testing.T) {
+	run := func() string {
+		stdout := &bytes.Buffer{}
+		r := golox.NewRuntime()
+		r.Stdout = stdout
+		r.Seed(42)
+		r.Run(bytes.NewBufferString("print random(); print randomInt(1, 100);"))
+		return stdout.String()
+	}
+
+	assert.Equal(t, run(), run())
+}
+
+func TestRuntime_RedefineNative(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "lib.lox"), []byte("var x = 1;"), 0644))
+
+	stdout := &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stdout = stdout
+	r.BasePath = dir
+
+	// natives survive include and subsequent runs like lines of REPL
+	r.Run(bytes.NewBufferString(`var max = 3; include "lib.lox"; print max;`))
+	r.Run(bytes.NewBufferString("var E = 1;"))
+	r.Run(bytes.NewBufferString("print E;"))
+	assert.Equal(t, "3\n1\n", stdout.String())
+}
+
+// applyFunc is apply(name, argument) native which calls global function name with argument
+type applyFunc struct{}
+
+func (af *applyFunc) Call(ctx *golox.NativeContext, arguments []interface{}) (interface{}, error) {
+	name, ok := arguments[0].(string)
+	if !ok {
+		return nil, ctx.Error("Name must be a string.")
+	}
+	function, err := ctx.Global(name)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Call(function, arguments[1])
+}
+
+func (af *applyFunc) Arity() int {
+	return 2
+}
+
+func TestNativeContext(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		stderr   string
+		given    string
+	}{
+		{name: "call back function", expected: "2\n", given: "fun inc(x) { return x + 1; }\nprint apply(\"inc\", 1);"},
+		{name: "create instance", expected: "Point instance\n1\n", given: "class Point { init(x) { this.x = x; } }\nvar p = apply(\"Point\", 1);\nprint p;\nprint p.x;"},
+		{name: "error at call site", stderr: "Name must be a string.\n[line 2]\n", given: "\napply(1, 2);"},
+		{name: "undefined global", stderr: "Undefined variable 'f'.\n[line 3]\n", given: "\n\napply(\"f\", 2);"},
+		{name: "arity mismatch", stderr: "Expected 0 arguments but got 1.\n[line 1]\n", given: "apply(\"clock\", 1);"},
+		{name: "error in callback", stderr: "Operand must be a number.\n[line 1]\n", given: "fun neg(x) { return -x; }\n\napply(\"neg\", \"a\");"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stdout = stdout
+			r.Stderr = stderr
+			r.Globals.Define("apply", golox.NewContextNativeFunction(&applyFunc{}))
+			r.Run(bytes.NewBufferString(tt.given))
+			assert.Equal(t, tt.stderr, stderr.String())
+			assert.Equal(t, tt.expected, stdout.String())
+		})
+	}
+}
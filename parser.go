@@ -5,6 +5,8 @@ type Parser struct {
 	runtime *Runtime
 	tokens  TokenList
 	current int
+	// err is the first syntax error
+	err error
 }
 
 // NewParser is constructor of Parser
@@ -16,18 +18,20 @@ func NewParser(runtime *Runtime, tokens TokenList) *Parser {
 	}
 }
 
-// Parse parses given tokens
+// Parse parses given tokens.
+// It recovers from syntax errors at statement boundaries to report as many errors as possible,
+// and returns the statements parsed successfully with the first error.
 func (p *Parser) Parse() ([]Stmt, error) {
 	statements := make([]Stmt, 0)
 	for !p.isAtEnd() {
 		stmt, err := p.declaration()
 		if err != nil {
-			return nil, err
+			continue
 		}
 		statements = append(statements, stmt)
 	}
 
-	return statements, nil
+	return statements, p.err
 }
 
 func (p *Parser) expression() (Expr, error) {
@@ -35,28 +39,32 @@ func (p *Parser) expression() (Expr, error) {
 }
 
 func (p *Parser) declaration() (Stmt, error) {
+	start := p.peek()
+	stmt, err := p.declarationBody()
+	if err != nil {
+		p.synchronize()
+		return nil, err
+	}
+	p.mark(stmt, start)
+	return stmt, nil
+}
+
+func (p *Parser) declarationBody() (Stmt, error) {
 	if p.match(ClassTT) {
 		return p.classDeclaration()
 	}
-	if p.match(FunTT) {
+	// fun (a) { ... } is a lambda in expression statement
+	if p.check(FunTT) && p.peekAt(1).Type != LeftParenTT {
+		p.advance()
 		return p.function("function")
 	}
 	if p.match(IncludeTT) {
 		return p.include()
 	}
 	if p.match(VarTT) {
-		stmt, err := p.varDecralation()
-		if err != nil {
-			p.synchronize()
-			return nil, err
-		}
-		return stmt, nil
-	}
-	stmt, err := p.statement()
-	if err != nil {
-		p.synchronize()
+		return p.varDecralation()
 	}
-	return stmt, nil
+	return p.statement()
 }
 
 func (p *Parser) classDeclaration() (Stmt, error) {
@@ -67,7 +75,10 @@ func (p *Parser) classDeclaration() (Stmt, error) {
 
 	var superclass *Variable
 	if p.match(LessTT) {
-		p.consume(IdentifierTT, "Expect superclass name.")
+		_, err = p.consume(IdentifierTT, "Expect superclass name.")
+		if err != nil {
+			return nil, err
+		}
 		superclass = NewVariable(p.previous()).(*Variable)
 	}
 	_, err = p.consume(LeftBraceTT, "Expect '{' before class body.")
@@ -77,20 +88,33 @@ func (p *Parser) classDeclaration() (Stmt, error) {
 
 	methods := make([]*Function, 0)
 	for !p.check(RightBraceTT) && !p.isAtEnd() {
-
+		start := p.peek()
 		fun, err := p.function("method")
 		if err != nil {
 			return nil, err
 		}
+		p.mark(fun, start)
 		methods = append(methods, fun.(*Function))
 	}
 
-	_, err = p.consume(RightBraceTT, "Expect '}' after class body")
+	_, err = p.consume(RightBraceTT, "Expect '}' after class body.")
+	if err != nil {
+		return nil, err
+	}
 
 	return NewClass(name, superclass, methods), nil
 }
 
 func (p *Parser) statement() (Stmt, error) {
+	start := p.peek()
+	stmt, err := p.statementBody()
+	if err == nil && stmt != nil {
+		p.mark(stmt, start)
+	}
+	return stmt, err
+}
+
+func (p *Parser) statementBody() (Stmt, error) {
 	if p.match(ForTT) {
 		return p.forStatement()
 	}
@@ -103,6 +127,9 @@ func (p *Parser) statement() (Stmt, error) {
 	if p.match(ReturnTT) {
 		return p.returnStatement()
 	}
+	if p.match(SwitchTT) {
+		return p.switchStatement()
+	}
 	if p.match(WhileTT) {
 		return p.whileStatement()
 	}
@@ -141,7 +168,7 @@ func (p *Parser) forStatement() (Stmt, error) {
 			return nil, err
 		}
 	}
-	_, err = p.consume(SemicolonTT, "Expect ';' after loop condition")
+	_, err = p.consume(SemicolonTT, "Expect ';' after loop condition.")
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +191,8 @@ func (p *Parser) forStatement() (Stmt, error) {
 		return nil, err
 	}
 
+	loop := NewForLoop(initializer, condition, increment, body)
+
 	if increment != nil {
 		body = NewBlock([]Stmt{body, NewExpression(increment)})
 	}
@@ -176,12 +205,34 @@ func (p *Parser) forStatement() (Stmt, error) {
 	if initializer != nil {
 		body = NewBlock([]Stmt{initializer, body})
 	}
+	p.runtime.ForLoops[body] = loop
 
 	return body, nil
 }
 
+// ForLoop keeps clauses of for statement which is desugared into while statement.
+type ForLoop struct {
+	Initializer Stmt
+	Condition   Expr
+	Increment   Expr
+	Body        Stmt
+}
+
+// NewForLoop is constructor of ForLoop
+func NewForLoop(initializer Stmt, condition Expr, increment Expr, body Stmt) *ForLoop {
+	return &ForLoop{
+		Initializer: initializer,
+		Condition:   condition,
+		Increment:   increment,
+		Body:        body,
+	}
+}
+
+// ifStatement parses if statement after 'if' or 'elseif'.
+// if (a) x; elseif (b) y; else z; is parsed as if (a) x; else if (b) y; else z;
 func (p *Parser) ifStatement() (Stmt, error) {
-	_, err := p.consume(LeftParenTT, "Expect '(' after 'if'.")
+	keyword := p.previous()
+	_, err := p.consume(LeftParenTT, "Expect '(' after '"+keyword.Lexeme+"'.")
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +240,7 @@ func (p *Parser) ifStatement() (Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = p.consume(RightParenTT, "Expect ')' after 'if'.")
+	_, err = p.consume(RightParenTT, "Expect ')' after if condition.")
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +252,14 @@ func (p *Parser) ifStatement() (Stmt, error) {
 	}
 
 	var elseBranch Stmt = nil
-	if p.match(ElseTT) {
+	if p.match(ElseifTT) {
+		start := p.previous()
+		elseBranch, err = p.ifStatement()
+		if err != nil {
+			return nil, err
+		}
+		p.mark(elseBranch, start)
+	} else if p.match(ElseTT) {
 		// _, err = p.consume(LeftBraceTT, "Expect '{' for if else block")
 		// if err != nil {
 		// 	return nil, err
@@ -220,7 +278,7 @@ func (p *Parser) printStatement() (Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = p.consumeTerm()
+	_, err = p.consume(SemicolonTT, "Expect ';' after value.")
 	if err != nil {
 		return nil, err
 	}
@@ -239,20 +297,112 @@ func (p *Parser) returnStatement() (Stmt, error) {
 		}
 	}
 
-	_, err = p.consumeTerm()
+	_, err = p.consume(SemicolonTT, "Expect ';' after return value.")
 	if err != nil {
 		return nil, err
 	}
 	return NewReturn(keyword, value), nil
 }
 
+func (p *Parser) switchStatement() (Stmt, error) {
+	keyword := p.previous()
+	_, err := p.consume(LeftParenTT, "Expect '(' after 'switch'.")
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(RightParenTT, "Expect ')' after switch value.")
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(LeftBraceTT, "Expect '{' before switch body.")
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]*Case, 0)
+	hasDefault := false
+	for !p.check(RightBraceTT) && !p.isAtEnd() {
+		c, err := p.switchCase()
+		if err != nil {
+			// skip to the next case as block does for statements
+			p.synchronize()
+			continue
+		}
+		if c.Values == nil {
+			if hasDefault {
+				p.runtime.ErrorTokenMessage(c.Keyword, "Switch can't have more than one default.")
+			}
+			hasDefault = true
+		}
+		cases = append(cases, c)
+	}
+
+	_, err = p.consume(RightBraceTT, "Expect '}' after switch body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSwitch(keyword, value, cases), nil
+}
+
+// switchCase parses a case or default arm. Values of default arm are nil.
+func (p *Parser) switchCase() (*Case, error) {
+	start := p.peek()
+	var values []Expr
+	if p.match(CaseTT) {
+		values = make([]Expr, 0)
+		for {
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if !p.match(CommaTT) {
+				break
+			}
+		}
+		_, err := p.consume(ColonTT, "Expect ':' after case values.")
+		if err != nil {
+			return nil, err
+		}
+	} else if p.match(DefaultTT) {
+		_, err := p.consume(ColonTT, "Expect ':' after 'default'.")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, p.NewParseError(p.peek(), "Expect 'case' or 'default' in switch body.")
+	}
+
+	body := make([]Stmt, 0)
+	for !p.check(CaseTT) && !p.check(DefaultTT) && !p.check(RightBraceTT) && !p.isAtEnd() {
+		stmt, err := p.declaration()
+		// the error is already reported and the parser is synchronized
+		if err != nil {
+			continue
+		}
+		body = append(body, stmt)
+	}
+
+	c := NewCase(start, values, body).(*Case)
+	p.mark(c, start)
+	return c, nil
+}
+
 func (p *Parser) whileStatement() (Stmt, error) {
-	_, err := p.consume(LeftParenTT, "Expect '(' for while body")
+	_, err := p.consume(LeftParenTT, "Expect '(' after 'while'.")
+	if err != nil {
+		return nil, err
+	}
 	condition, err := p.expression()
 	if err != nil {
 		return nil, err
 	}
-	_, err = p.consume(RightParenTT, "Expect ')' for while body")
+	_, err = p.consume(RightParenTT, "Expect ')' after condition.")
 	if err != nil {
 		return nil, err
 	}
@@ -279,7 +429,7 @@ func (p *Parser) varDecralation() (Stmt, error) {
 		}
 	}
 
-	_, err = p.consumeTerm()
+	_, err = p.consume(SemicolonTT, "Expect ';' after variable declaration.")
 	if err != nil {
 		return nil, err
 	}
@@ -293,8 +443,7 @@ func (p *Parser) expressionStatement() (Stmt, error) {
 		return nil, err
 	}
 
-	_, err = p.consume(SemicolonTT, "Expect ';' after expression")
-	// _, err = p.consumeTerm()
+	_, err = p.consume(SemicolonTT, "Expect ';' after expression.")
 	if err != nil {
 		return nil, err
 	}
@@ -312,12 +461,31 @@ func (p *Parser) function(kind string) (Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
+	parameters, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(LeftBraceTT, "Expect '{' before "+kind+" body.")
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	return NewFunction(name, parameters, body), nil
+}
+
+// parameters parses parameters of function after '(' until ')'
+func (p *Parser) parameters() ([]*Token, error) {
 	parameters := make([]*Token, 0)
 
 	if !p.check(RightParenTT) {
 		for {
 			if len(parameters) >= 255 {
-				return nil, p.NewParseError(p.peek(), "Can't have more than 255 parameters.")
+				// report without synchronizing because the parser isn't confused
+				p.NewParseError(p.peek(), "Can't have more than 255 parameters.")
 			}
 
 			token, err := p.consume(IdentifierTT, "Expect parameter name.")
@@ -332,12 +500,25 @@ func (p *Parser) function(kind string) (Stmt, error) {
 		}
 	}
 
-	_, err = p.consume(RightParenTT, "Expect ')' after parameters.")
+	_, err := p.consume(RightParenTT, "Expect ')' after parameters.")
 	if err != nil {
 		return nil, err
 	}
+	return parameters, nil
+}
 
-	_, err = p.consume(LeftBraceTT, "Expected an indented block as "+kind+" body.")
+// lambda parses anonymous function after 'fun'
+func (p *Parser) lambda() (Expr, error) {
+	keyword := p.previous()
+	_, err := p.consume(LeftParenTT, "Expect '(' after 'fun'.")
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(LeftBraceTT, "Expect '{' before lambda body.")
 	if err != nil {
 		return nil, err
 	}
@@ -345,15 +526,83 @@ func (p *Parser) function(kind string) (Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewFunction(name, parameters, body), nil
+
+	function := NewFunction(nil, parameters, body).(*Function)
+	p.mark(function, keyword)
+	return NewLambda(keyword, function), nil
+}
+
+// arrowLambda parses (a, b) => expr, a => expr and (a) => { ... }
+func (p *Parser) arrowLambda() (Expr, error) {
+	start := p.peek()
+	parameters := make([]*Token, 0)
+	if p.match(IdentifierTT) {
+		parameters = append(parameters, p.previous())
+	} else {
+		p.advance() // (
+		var err error
+		parameters, err = p.parameters()
+		if err != nil {
+			return nil, err
+		}
+	}
+	arrow, err := p.consume(ArrowTT, "Expect '=>' after lambda parameters.")
+	if err != nil {
+		return nil, err
+	}
+
+	var body []Stmt
+	if p.match(LeftBraceTT) {
+		body, err = p.block()
+	} else {
+		var value Expr
+		value, err = p.expression()
+		body = []Stmt{NewReturn(arrow, value)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	function := NewFunction(nil, parameters, body).(*Function)
+	p.mark(function, start)
+	return NewLambda(arrow, function), nil
+}
+
+// isArrowLambda reports whether an arrow lambda starts at the current token.
+// It looks ahead for a => b or (a, b) => without consuming tokens.
+func (p *Parser) isArrowLambda() bool {
+	if p.check(IdentifierTT) {
+		return p.peekAt(1).Type == ArrowTT
+	}
+	if !p.check(LeftParenTT) {
+		return false
+	}
+
+	k := 1
+	if p.peekAt(k).Type != RightParenTT {
+		for {
+			if p.peekAt(k).Type != IdentifierTT {
+				return false
+			}
+			k++
+			if p.peekAt(k).Type != CommaTT {
+				break
+			}
+			k++
+		}
+		if p.peekAt(k).Type != RightParenTT {
+			return false
+		}
+	}
+	return p.peekAt(k+1).Type == ArrowTT
 }
 
 func (p *Parser) include() (Stmt, error) {
-	s, err := p.consume(StringTT, "Expect string after include")
+	s, err := p.consume(StringTT, "Expect string after include.")
 	if err != nil {
 		return nil, err
 	}
-	_, err = p.consumeTerm()
+	_, err = p.consume(SemicolonTT, "Expect ';' after include.")
 	if err != nil {
 		return nil, err
 	}
@@ -364,18 +613,22 @@ func (p *Parser) block() ([]Stmt, error) {
 	statements := make([]Stmt, 0)
 	for !p.check(RightBraceTT) && !p.isAtEnd() {
 		stmt, err := p.declaration()
+		// the error is already reported and the parser is synchronized
 		if err != nil {
-			return nil, err
+			continue
 		}
 		statements = append(statements, stmt)
 	}
 
-	p.consume(RightBraceTT, "Expect '}' after block.")
+	_, err := p.consume(RightBraceTT, "Expect '}' after block.")
+	if err != nil {
+		return nil, err
+	}
 	return statements, nil
 }
 
 func (p *Parser) assignment() (Expr, error) {
-	expr, err := p.or()
+	expr, err := p.conditional()
 	if err != nil {
 		return nil, err
 	}
@@ -392,9 +645,73 @@ func (p *Parser) assignment() (Expr, error) {
 		} else if expr.IsType(&Get{}) {
 			get := expr.(*Get)
 			return NewSet(get.Object, get.Name, value), nil
+		} else if expr.IsType(&Index{}) {
+			index := expr.(*Index)
+			return NewSetIndex(index.Object, index.Bracket, index.Index, value), nil
 		}
 
 		p.runtime.ErrorTokenMessage(equals, "Invalid assignment target.")
+	} else if p.match(PlusEqualTT, MinusEqualTT, StarEqualTT, SlashEqualTT) {
+		operator := p.previous()
+		value, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		if isCompoundTarget(expr) {
+			return NewCompoundAssign(expr, operator, value, false), nil
+		}
+
+		p.runtime.ErrorTokenMessage(operator, "Invalid assignment target.")
+	}
+
+	return expr, nil
+}
+
+// isCompoundTarget reports whether expr can be the target of compound assignment, increment and decrement
+func isCompoundTarget(expr Expr) bool {
+	return expr.IsType(&Variable{}) || expr.IsType(&Get{})
+}
+
+// conditional is right-associative: a ? b : c ? d : e is a ? b : (c ? d : e)
+func (p *Parser) conditional() (Expr, error) {
+	expr, err := p.coalesce()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(QuestionTT) {
+		thenBranch, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(ColonTT, "Expect ':' after then branch of conditional expression.")
+		if err != nil {
+			return nil, err
+		}
+		elseBranch, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewConditional(expr, thenBranch, elseBranch)
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) coalesce() (Expr, error) {
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(QuestionQuestionTT) {
+		operator := p.previous()
+		right, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewLogical(expr, operator, right)
 	}
 
 	return expr, nil
@@ -455,14 +772,14 @@ func (p *Parser) equality() (Expr, error) {
 }
 
 func (p *Parser) comparison() (Expr, error) {
-	expr, err := p.term()
+	expr, err := p.bitOr()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(GreaterTT, GreaterEqualTT, LessTT, LessEqualTT) {
 		operator := p.previous()
-		right, err := p.term()
+		right, err := p.bitOr()
 		if err != nil {
 			return nil, err
 		}
@@ -473,6 +790,78 @@ func (p *Parser) comparison() (Expr, error) {
 	return expr, nil
 }
 
+func (p *Parser) bitOr() (Expr, error) {
+	expr, err := p.bitXor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(PipeTT) {
+		operator := p.previous()
+		right, err := p.bitXor()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) bitXor() (Expr, error) {
+	expr, err := p.bitAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(CaretTT) {
+		operator := p.previous()
+		right, err := p.bitAnd()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) bitAnd() (Expr, error) {
+	expr, err := p.shift()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(AmpersandTT) {
+		operator := p.previous()
+		right, err := p.shift()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, nil
+}
+
+func (p *Parser) shift() (Expr, error) {
+	expr, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(LessLessTT, GreaterGreaterTT) {
+		operator := p.previous()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+
+	return expr, nil
+}
+
 func (p *Parser) term() (Expr, error) {
 	expr, err := p.factor()
 	if err != nil {
@@ -497,7 +886,7 @@ func (p *Parser) factor() (Expr, error) {
 		return nil, err
 	}
 
-	for p.match(SlashTT, StarTT) {
+	for p.match(SlashTT, StarTT, PercentTT, TildeSlashTT) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -511,7 +900,7 @@ func (p *Parser) factor() (Expr, error) {
 }
 
 func (p *Parser) unary() (Expr, error) {
-	if p.match(BangTT, MinusTT) {
+	if p.match(BangTT, MinusTT, TildeTT) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -519,8 +908,45 @@ func (p *Parser) unary() (Expr, error) {
 		}
 		return NewUnary(operator, right), nil
 	}
+	return p.power()
+}
+
+// power binds tighter than unary operators on its left, so -2 ** 2 is -(2 ** 2).
+// It's right-associative and its exponent may have unary operators, as in 2 ** -1.
+func (p *Parser) power() (Expr, error) {
+	expr, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(StarStarTT) {
+		operator := p.previous()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
 
-	return p.call()
+	return expr, nil
+}
+
+func (p *Parser) postfix() (Expr, error) {
+	expr, err := p.call()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(PlusPlusTT, MinusMinusTT) {
+		operator := p.previous()
+		if !isCompoundTarget(expr) {
+			p.runtime.ErrorTokenMessage(operator, "Invalid increment target.")
+			return expr, nil
+		}
+		return NewCompoundAssign(expr, operator, NewLiteral(1.0), true), nil
+	}
+
+	return expr, nil
 }
 
 func (p *Parser) call() (Expr, error) {
@@ -529,6 +955,7 @@ func (p *Parser) call() (Expr, error) {
 		return nil, err
 	}
 
+	optional := false
 	for {
 		if p.match(LeftParenTT) {
 			expr, err = p.finishCall(expr)
@@ -540,12 +967,34 @@ func (p *Parser) call() (Expr, error) {
 			if err != nil {
 				return nil, err
 			}
-			expr = NewGet(expr, name)
+			expr = NewGet(expr, name, false)
+		} else if p.match(QuestionDotTT) {
+			name, err := p.consume(IdentifierTT, "Expect property name after '?.'.")
+			if err != nil {
+				return nil, err
+			}
+			expr = NewGet(expr, name, true)
+			optional = true
+		} else if p.match(LeftBracketTT) {
+			bracket := p.previous()
+			index, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			_, err = p.consume(RightBracketTT, "Expect ']' after index.")
+			if err != nil {
+				return nil, err
+			}
+			expr = NewIndex(expr, bracket, index)
 		} else {
 			break
 		}
 	}
 
+	if optional {
+		// the rest of the chain is skipped when ?. meets nil
+		return NewOptionalChain(expr), nil
+	}
 	return expr, nil
 }
 
@@ -559,7 +1008,7 @@ func (p *Parser) finishCall(callee Expr) (Expr, error) {
 			}
 
 			if len(arguments) >= 255 {
-				return nil, p.NewParseError(p.peek(), "Can't have more than 255 arguments.")
+				p.NewParseError(p.peek(), "Can't have more than 255 arguments.")
 			}
 
 			arguments = append(arguments, expr)
@@ -579,6 +1028,10 @@ func (p *Parser) finishCall(callee Expr) (Expr, error) {
 }
 
 func (p *Parser) primary() (Expr, error) {
+	if closesInterpolation(p.peek()) {
+		// the rest of the string isn't an operand, e.g. "${1 +}"
+		return nil, p.NewParseError(p.peek(), "Expect expression.")
+	}
 	if p.match(FalseTT) {
 		return NewLiteral(false), nil
 	}
@@ -589,7 +1042,25 @@ func (p *Parser) primary() (Expr, error) {
 		return NewLiteral(nil), nil
 	}
 	if p.match(NumberTT, StringTT) {
-		return NewLiteral(p.previous().Literal), nil
+		literal := NewLiteral(p.previous().Literal)
+		// keeps the notation such as 0xFF and raw strings for the formatter
+		p.runtime.LiteralTokens[literal] = p.previous()
+		return literal, nil
+	}
+	if p.match(InterpolationTT) {
+		return p.interpolation()
+	}
+	if p.match(LeftBracketTT) {
+		return p.list()
+	}
+	if p.match(MatchTT) {
+		return p.matchExpression()
+	}
+	if p.match(FunTT) {
+		return p.lambda()
+	}
+	if p.isArrowLambda() {
+		return p.arrowLambda()
 	}
 	if p.match(SuperTT) {
 		keyword := p.previous()
@@ -597,7 +1068,7 @@ func (p *Parser) primary() (Expr, error) {
 		if err != nil {
 			return nil, err
 		}
-		method, err := p.consume(IdentifierTT, "Expect supercrlass method name.")
+		method, err := p.consume(IdentifierTT, "Expect superclass method name.")
 		if err != nil {
 			return nil, err
 		}
@@ -626,6 +1097,230 @@ func (p *Parser) primary() (Expr, error) {
 	return nil, p.NewParseError(p.peek(), "Expect expression.")
 }
 
+// list parses list literal such as [1, 2, 3]. A trailing comma is allowed.
+func (p *Parser) list() (Expr, error) {
+	bracket := p.previous()
+	elements := make([]Expr, 0)
+	for !p.check(RightBracketTT) {
+		element, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		if !p.match(CommaTT) {
+			break
+		}
+	}
+	_, err := p.consume(RightBracketTT, "Expect ']' after list elements.")
+	if err != nil {
+		return nil, err
+	}
+	return NewList(bracket, elements), nil
+}
+
+// matchExpression parses match (value) { pattern => expr, ... } after 'match'.
+// A trailing comma after the last arm is allowed.
+func (p *Parser) matchExpression() (Expr, error) {
+	keyword := p.previous()
+	_, err := p.consume(LeftParenTT, "Expect '(' after 'match'.")
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(RightParenTT, "Expect ')' after match value.")
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.consume(LeftBraceTT, "Expect '{' before match arms.")
+	if err != nil {
+		return nil, err
+	}
+
+	arms := make([]*MatchArm, 0)
+	for !p.check(RightBraceTT) && !p.isAtEnd() {
+		pattern, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+		arrow, err := p.consume(ArrowTT, "Expect '=>' after pattern.")
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		arms = append(arms, NewMatchArm(pattern, arrow, body))
+		// trailing comma is allowed
+		if !p.match(CommaTT) {
+			break
+		}
+	}
+	if len(arms) == 0 {
+		return nil, p.NewParseError(p.peek(), "Expect at least one match arm.")
+	}
+
+	_, err = p.consume(RightBraceTT, "Expect '}' after match arms.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMatch(keyword, value, arms), nil
+}
+
+// pattern parses alternatives of patterns separated by '|'
+func (p *Parser) pattern() (Pattern, error) {
+	pattern, err := p.primaryPattern()
+	if err != nil {
+		return nil, err
+	}
+	if !p.check(PipeTT) {
+		return pattern, nil
+	}
+
+	alternatives := []Pattern{pattern}
+	for p.match(PipeTT) {
+		pipe := p.previous()
+		alternative, err := p.primaryPattern()
+		if err != nil {
+			return nil, err
+		}
+		if bindingNames(alternative) != bindingNames(pattern) {
+			p.runtime.ErrorTokenMessage(pipe, "Alternatives of a pattern must bind the same variables.")
+		}
+		alternatives = append(alternatives, alternative)
+	}
+
+	return &AlternativePattern{Alternatives: alternatives}, nil
+}
+
+func (p *Parser) primaryPattern() (Pattern, error) {
+	if p.match(FalseTT) {
+		return &LiteralPattern{Value: false}, nil
+	}
+	if p.match(TrueTT) {
+		return &LiteralPattern{Value: true}, nil
+	}
+	if p.match(NilTT) {
+		return &LiteralPattern{Value: nil}, nil
+	}
+	if p.match(NumberTT) {
+		return &LiteralPattern{Value: p.previous().Literal, Lexeme: p.previous().Lexeme}, nil
+	}
+	if p.match(StringTT) {
+		if isRawString(p.previous()) {
+			return &LiteralPattern{Value: p.previous().Literal, Lexeme: p.previous().Lexeme}, nil
+		}
+		return &LiteralPattern{Value: p.previous().Literal}, nil
+	}
+	if p.match(MinusTT) {
+		number, err := p.consume(NumberTT, "Expect number after '-' in pattern.")
+		if err != nil {
+			return nil, err
+		}
+		return &LiteralPattern{Value: -number.Literal.(float64), Lexeme: "-" + number.Lexeme}, nil
+	}
+	if p.match(LeftBracketTT) {
+		return p.listPattern()
+	}
+	if p.match(IdentifierTT) {
+		name := p.previous()
+		if name.Lexeme == "_" {
+			return &WildcardPattern{Token: name}, nil
+		}
+		if p.match(LeftParenTT) {
+			return p.classPattern(name)
+		}
+		return &BindingPattern{Name: name}, nil
+	}
+
+	return nil, p.NewParseError(p.peek(), "Expect pattern.")
+}
+
+func (p *Parser) listPattern() (Pattern, error) {
+	bracket := p.previous()
+	elements := make([]Pattern, 0)
+	for !p.check(RightBracketTT) {
+		element, err := p.pattern()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		// trailing comma is allowed
+		if !p.match(CommaTT) {
+			break
+		}
+	}
+	_, err := p.consume(RightBracketTT, "Expect ']' after list pattern.")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListPattern{Bracket: bracket, Elements: elements}, nil
+}
+
+func (p *Parser) classPattern(class *Token) (Pattern, error) {
+	fields := make([]*FieldPattern, 0)
+	for !p.check(RightParenTT) {
+		name, err := p.consume(IdentifierTT, "Expect field name in class pattern.")
+		if err != nil {
+			return nil, err
+		}
+		var pattern Pattern = &BindingPattern{Name: name}
+		if p.match(ColonTT) {
+			pattern, err = p.pattern()
+			if err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, &FieldPattern{Name: name, Pattern: pattern})
+		if !p.match(CommaTT) {
+			break
+		}
+	}
+	_, err := p.consume(RightParenTT, "Expect ')' after field patterns.")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClassPattern{Class: NewVariable(class).(*Variable), Fields: fields}, nil
+}
+
+// interpolation parses string with ${...}.
+// Parts of the string and embedded expressions appear alternately in its parts.
+func (p *Parser) interpolation() (Expr, error) {
+	parts := []Expr{NewLiteral(p.previous().Literal)}
+	for {
+		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, expr)
+
+		if p.match(InterpolationTT) {
+			parts = append(parts, NewLiteral(p.previous().Literal))
+			continue
+		}
+		rest, err := p.consume(StringTT, "Expect '}' after interpolated expression.")
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, NewLiteral(rest.Literal))
+		return NewInterpolation(parts), nil
+	}
+}
+
+// mark records where stmt starts and ends in the source code.
+func (p *Parser) mark(stmt Stmt, start *Token) {
+	position := NewPosition(start.Line, start.Column)
+	position.File = p.runtime.File
+	position.EndLine = p.previous().Line
+	p.runtime.Positions[stmt] = position
+}
+
 func (p *Parser) match(types ...TokenType) bool {
 	for _, typ := range types {
 		if p.check(typ) {
@@ -645,15 +1340,6 @@ func (p *Parser) consume(typ TokenType, message string) (*Token, error) {
 	return nil, p.NewParseError(p.peek(), message)
 }
 
-func (p *Parser) consumeTerm() (*Token, error) {
-	if p.check(SemicolonTT) {
-		t := p.advance()
-		return t, nil
-	}
-
-	return nil, p.NewParseError(p.peek(), "Expect ';' after expression")
-}
-
 func (p *Parser) check(typ TokenType) bool {
 	if p.isAtEnd() {
 		return false
@@ -676,6 +1362,14 @@ func (p *Parser) peek() *Token {
 	return p.tokens[p.current]
 }
 
+// peekAt returns the token n tokens after the current one, or EOF
+func (p *Parser) peekAt(n int) *Token {
+	if p.current+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.current+n]
+}
+
 func (p *Parser) previous() *Token {
 	return p.tokens[p.current-1]
 }
@@ -709,6 +1403,8 @@ func (p *Parser) synchronize() {
 			return
 		case ReturnTT:
 			return
+		case SwitchTT, CaseTT, DefaultTT:
+			return
 		}
 
 		p.advance()
@@ -717,6 +1413,16 @@ func (p *Parser) synchronize() {
 
 // NewParseError is constructor of ParseError
 func (p *Parser) NewParseError(token *Token, message string) error {
+	if closesInterpolation(token) {
+		// reports at '}' rather than the rest of the string
+		brace := NewToken(RightBraceTT, "}", nil, token.Line)
+		brace.Column = token.Column
+		token = brace
+	}
 	p.runtime.ErrorTokenMessage(token, message)
-	return ParseError.New(token, message)
+	err := ParseError.New(token, message)
+	if p.err == nil {
+		p.err = err
+	}
+	return err
 }
@@ -1,6 +1,7 @@
 package golox_test
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
@@ -67,6 +68,41 @@ func TestParser(t *testing.T) {
 				golox.NewToken(golox.EOFTT, "", nil, 1),
 			},
 		},
+		{
+			name: "if (true) print 1; elseif (false) print 2; else print 3;",
+			expected: []golox.Stmt{
+				golox.NewIf(
+					golox.NewLiteral(true),
+					golox.NewPrint(golox.NewLiteral(1.0)),
+					golox.NewIf(
+						golox.NewLiteral(false),
+						golox.NewPrint(golox.NewLiteral(2.0)),
+						golox.NewPrint(golox.NewLiteral(3.0)),
+					),
+				),
+			},
+			given: golox.TokenList{
+				golox.NewToken(golox.IfTT, "if", nil, 1),
+				golox.NewToken(golox.LeftParenTT, "(", nil, 1),
+				golox.NewToken(golox.TrueTT, "true", nil, 1),
+				golox.NewToken(golox.RightParenTT, ")", nil, 1),
+				golox.NewToken(golox.PrintTT, "print", nil, 1),
+				golox.NewToken(golox.NumberTT, "1", 1.0, 1),
+				golox.NewToken(golox.SemicolonTT, ";", nil, 1),
+				golox.NewToken(golox.ElseifTT, "elseif", nil, 2),
+				golox.NewToken(golox.LeftParenTT, "(", nil, 2),
+				golox.NewToken(golox.FalseTT, "false", nil, 2),
+				golox.NewToken(golox.RightParenTT, ")", nil, 2),
+				golox.NewToken(golox.PrintTT, "print", nil, 2),
+				golox.NewToken(golox.NumberTT, "2", 2.0, 2),
+				golox.NewToken(golox.SemicolonTT, ";", nil, 2),
+				golox.NewToken(golox.ElseTT, "else", nil, 3),
+				golox.NewToken(golox.PrintTT, "print", nil, 3),
+				golox.NewToken(golox.NumberTT, "3", 3.0, 3),
+				golox.NewToken(golox.SemicolonTT, ";", nil, 3),
+				golox.NewToken(golox.EOFTT, "", nil, 3),
+			},
+		},
 		{
 			name: "if true { print 1; } else { print 2; }",
 			expected: []golox.Stmt{
@@ -286,3 +322,57 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ErrorRecovery(t *testing.T) {
+	var tests = []struct {
+		name       string
+		given      string
+		statements int
+		expected   string
+	}{
+		{
+			name:       "report errors of each statement",
+			given:      "var = 1;\nprint 1\nprint 2;\nprint 3;\n",
+			statements: 1,
+			expected:   "[line 1] Error at '=': Expect variable name.\n[line 3] Error at 'print': Expect ';' after value.\n",
+		},
+		{
+			name:       "recover inside block",
+			given:      "{\n  1 +;\n  var a = 2;\n}\nreturn;\n",
+			statements: 2,
+			expected:   "[line 2] Error at ';': Expect expression.\n",
+		},
+		{
+			name:       "missing superclass name",
+			given:      "class A < {}\nvar b;\n",
+			statements: 1,
+			expected:   "[line 1] Error at '{': Expect superclass name.\n",
+		},
+		{
+			name:       "missing operand in interpolation",
+			given:      "var x = 1;\nprint \"a ${x +} b\";\nprint x;\n",
+			statements: 2,
+			expected:   "[line 2] Error at '}': Expect expression.\n",
+		},
+		{
+			name:       "unclosed paren in interpolation",
+			given:      "print \"${(1}\";\n",
+			statements: 0,
+			expected:   "[line 1] Error at '}': Expect ')' after expression.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stderr = stderr
+			tokens := golox.NewScanner(r, bytes.NewBufferString(tt.given)).ScanTokens()
+			statements, err := golox.NewParser(r, tokens).Parse()
+			assert.Error(t, err)
+			assert.Equal(t, tt.statements, len(statements))
+			assert.Equal(t, tt.expected, stderr.String())
+		})
+	}
+}
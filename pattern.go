@@ -0,0 +1,183 @@
+package golox
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pattern is pattern of an arm of match expression
+type Pattern interface {
+	// String returns source code of the pattern
+	String() string
+}
+
+// MatchArm is an arm of match expression
+type MatchArm struct {
+	Pattern Pattern
+	Arrow   *Token
+	Body    Expr
+}
+
+// NewMatchArm is constructor of MatchArm
+func NewMatchArm(pattern Pattern, arrow *Token, body Expr) *MatchArm {
+	return &MatchArm{
+		Pattern: pattern,
+		Arrow:   arrow,
+		Body:    body,
+	}
+}
+
+// LiteralPattern matches a value equal to the literal
+type LiteralPattern struct {
+	Value interface{}
+	// Lexeme is source code of number literal such as 0xFF and -1_000 or raw string. It's empty for other literals.
+	Lexeme string
+}
+
+func (lp *LiteralPattern) String() string {
+	if lp.Lexeme != "" {
+		return lp.Lexeme
+	}
+	switch v := lp.Value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return stringfy(lp.Value)
+}
+
+// WildcardPattern matches any value without binding it. It's written as _.
+type WildcardPattern struct {
+	Token *Token
+}
+
+func (wp *WildcardPattern) String() string {
+	return "_"
+}
+
+// BindingPattern matches any value and binds it to the name
+type BindingPattern struct {
+	Name *Token
+}
+
+func (bp *BindingPattern) String() string {
+	return bp.Name.Lexeme
+}
+
+// ListPattern matches a list of the same length whose elements match the patterns
+type ListPattern struct {
+	Bracket  *Token
+	Elements []Pattern
+}
+
+func (lp *ListPattern) String() string {
+	elements := make([]string, 0, len(lp.Elements))
+	for _, element := range lp.Elements {
+		elements = append(elements, element.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// ClassPattern matches an instance of the class or its subclasses whose fields match the patterns
+type ClassPattern struct {
+	Class  *Variable
+	Fields []*FieldPattern
+}
+
+func (cp *ClassPattern) String() string {
+	fields := make([]string, 0, len(cp.Fields))
+	for _, field := range cp.Fields {
+		fields = append(fields, field.String())
+	}
+	return cp.Class.Name.Lexeme + "(" + strings.Join(fields, ", ") + ")"
+}
+
+// FieldPattern matches a field of an instance.
+// Point(x) binds field x to variable x, and Point(x: 0) matches if field x equals 0.
+type FieldPattern struct {
+	Name    *Token
+	Pattern Pattern
+}
+
+func (fp *FieldPattern) String() string {
+	if bp, ok := fp.Pattern.(*BindingPattern); ok && bp.Name == fp.Name {
+		return fp.Name.Lexeme
+	}
+	return fp.Name.Lexeme + ": " + fp.Pattern.String()
+}
+
+// AlternativePattern matches if any of the patterns matches. All of them bind the same variables.
+type AlternativePattern struct {
+	Alternatives []Pattern
+}
+
+func (ap *AlternativePattern) String() string {
+	alternatives := make([]string, 0, len(ap.Alternatives))
+	for _, alternative := range ap.Alternatives {
+		alternatives = append(alternatives, alternative.String())
+	}
+	return strings.Join(alternatives, " | ")
+}
+
+// patternBindings returns names which the pattern binds
+func patternBindings(pattern Pattern) []*Token {
+	switch p := pattern.(type) {
+	case *BindingPattern:
+		return []*Token{p.Name}
+	case *ListPattern:
+		names := make([]*Token, 0)
+		for _, element := range p.Elements {
+			names = append(names, patternBindings(element)...)
+		}
+		return names
+	case *ClassPattern:
+		names := make([]*Token, 0)
+		for _, field := range p.Fields {
+			names = append(names, patternBindings(field.Pattern)...)
+		}
+		return names
+	case *AlternativePattern:
+		// every alternative binds the same variables
+		return patternBindings(p.Alternatives[0])
+	}
+	return nil
+}
+
+// patternClasses returns class variables referred by the pattern
+func patternClasses(pattern Pattern) []*Variable {
+	switch p := pattern.(type) {
+	case *ListPattern:
+		classes := make([]*Variable, 0)
+		for _, element := range p.Elements {
+			classes = append(classes, patternClasses(element)...)
+		}
+		return classes
+	case *ClassPattern:
+		classes := []*Variable{p.Class}
+		for _, field := range p.Fields {
+			classes = append(classes, patternClasses(field.Pattern)...)
+		}
+		return classes
+	case *AlternativePattern:
+		classes := make([]*Variable, 0)
+		for _, alternative := range p.Alternatives {
+			classes = append(classes, patternClasses(alternative)...)
+		}
+		return classes
+	}
+	return nil
+}
+
+// bindingNames returns sorted names which the pattern binds
+func bindingNames(pattern Pattern) string {
+	names := make([]string, 0)
+	for _, name := range patternBindings(pattern) {
+		names = append(names, name.Lexeme)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
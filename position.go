@@ -0,0 +1,25 @@
+package golox
+
+import "fmt"
+
+// Position is location of a statement in source code
+type Position struct {
+	File    string
+	Line    int
+	Column  int
+	EndLine int
+}
+
+// NewPosition is constructor of Position
+func NewPosition(line, column int) *Position {
+	return &Position{
+		Line:    line,
+		Column:  column,
+		EndLine: line,
+	}
+}
+
+// String stringfy Position
+func (p *Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
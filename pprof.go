@@ -0,0 +1,175 @@
+package golox
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+)
+
+// field numbers of profile.proto used by pprof
+const (
+	profileSampleType   = 1
+	profileSample       = 2
+	profileLocation     = 4
+	profileFunction     = 5
+	profileStringTable  = 6
+	profileTimeNanos    = 9
+	profileDuration     = 10
+	profilePeriodType   = 11
+	profilePeriod       = 12
+	valueTypeType       = 1
+	valueTypeUnit       = 2
+	sampleLocationID    = 1
+	sampleValue         = 2
+	locationID          = 1
+	locationLine        = 4
+	lineFunctionID      = 1
+	lineLine            = 2
+	functionID          = 1
+	functionName        = 2
+	functionSystemName  = 3
+	functionFilename    = 4
+	functionStartLine   = 5
+	wireVarint          = 0
+	wireLengthDelimited = 2
+)
+
+// WritePprof writes gzipped profile in the format of github.com/google/pprof.
+// Each function is a location, and samples have call count and self time per call stack.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	enc := &protoEncoder{strings: map[string]int64{"": 0}, table: []string{""}}
+
+	profile := &protoBuffer{}
+	profile.message(profileSampleType, enc.valueType("calls", "count"))
+	profile.message(profileSampleType, enc.valueType("time", "nanoseconds"))
+
+	// function and location ids are the same and start from 1
+	ids := make(map[*FunctionProfile]uint64)
+	for _, fp := range p.Functions() {
+		id := uint64(len(ids) + 1)
+		ids[fp] = id
+
+		function := &protoBuffer{}
+		function.varint(functionID, id)
+		name := fp.Name
+		if fp == p.script {
+			// pprof drops <...> from names as C++ template arguments
+			name = "script"
+		}
+		function.varint(functionName, uint64(enc.str(name)))
+		function.varint(functionSystemName, uint64(enc.str(name)))
+		function.varint(functionFilename, uint64(enc.str(displayFile(fp.File))))
+		function.varint(functionStartLine, uint64(fp.Line))
+		profile.message(profileFunction, function)
+
+		line := &protoBuffer{}
+		line.varint(lineFunctionID, id)
+		line.varint(lineLine, uint64(fp.Line))
+		location := &protoBuffer{}
+		location.varint(locationID, id)
+		location.message(locationLine, line)
+		profile.message(profileLocation, location)
+	}
+
+	for _, s := range p.sortedSamples() {
+		locations := make([]uint64, 0, len(s.stack))
+		for _, fp := range s.stack {
+			locations = append(locations, ids[fp])
+		}
+		sample := &protoBuffer{}
+		sample.packed(sampleLocationID, locations)
+		sample.packed(sampleValue, []uint64{uint64(s.calls), uint64(s.self.Nanoseconds())})
+		profile.message(profileSample, sample)
+	}
+
+	profile.varint(profileTimeNanos, uint64(p.started.UnixNano()))
+	profile.varint(profileDuration, uint64(p.duration.Nanoseconds()))
+	profile.message(profilePeriodType, enc.valueType("time", "nanoseconds"))
+	profile.varint(profilePeriod, 1)
+	for _, s := range enc.table {
+		profile.bytes(profileStringTable, []byte(s))
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.buf); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// sortedSamples returns samples in deterministic order
+func (p *Profiler) sortedSamples() []*stackSample {
+	samples := make([]*stackSample, 0, len(p.samples))
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		samples = append(samples, p.samples[key])
+	}
+	return samples
+}
+
+// protoEncoder holds string table of the profile
+type protoEncoder struct {
+	strings map[string]int64
+	table   []string
+}
+
+func (e *protoEncoder) str(s string) int64 {
+	if id, ok := e.strings[s]; ok {
+		return id
+	}
+	id := int64(len(e.table))
+	e.strings[s] = id
+	e.table = append(e.table, s)
+	return id
+}
+
+func (e *protoEncoder) valueType(typ, unit string) *protoBuffer {
+	vt := &protoBuffer{}
+	vt.varint(valueTypeType, uint64(e.str(typ)))
+	vt.varint(valueTypeUnit, uint64(e.str(unit)))
+	return vt
+}
+
+// protoBuffer encodes protocol buffers wire format
+type protoBuffer struct {
+	buf []byte
+}
+
+func (b *protoBuffer) rawVarint(x uint64) {
+	for x >= 0x80 {
+		b.buf = append(b.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	b.buf = append(b.buf, byte(x))
+}
+
+func (b *protoBuffer) key(field, wire int) {
+	b.rawVarint(uint64(field<<3 | wire))
+}
+
+func (b *protoBuffer) varint(field int, x uint64) {
+	b.key(field, wireVarint)
+	b.rawVarint(x)
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	b.key(field, wireLengthDelimited)
+	b.rawVarint(uint64(len(data)))
+	b.buf = append(b.buf, data...)
+}
+
+func (b *protoBuffer) message(field int, m *protoBuffer) {
+	b.bytes(field, m.buf)
+}
+
+func (b *protoBuffer) packed(field int, xs []uint64) {
+	p := &protoBuffer{}
+	for _, x := range xs {
+		p.rawVarint(x)
+	}
+	b.bytes(field, p.buf)
+}
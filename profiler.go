@@ -0,0 +1,249 @@
+package golox
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// scriptName is the name of pseudo function which represents top-level code
+const scriptName = "<script>"
+
+// FunctionProfile is statistics of a Lox function
+type FunctionProfile struct {
+	Name       string
+	File       string
+	Line       int
+	Calls      int
+	Cumulative time.Duration
+	Self       time.Duration
+}
+
+// LineProfile is execution count of a line
+type LineProfile struct {
+	File  string
+	Line  int
+	Count int
+}
+
+// profileCall is an active function call
+type profileCall struct {
+	profile *FunctionProfile
+	start   time.Time
+	// children is the time spent in callees
+	children time.Duration
+	// last is the last statement executed in the call
+	last    Stmt
+	lastPos *Position
+}
+
+// Profiler records call counts and time of functions and execution counts of lines.
+// Register it by Runtime.AddTracer and call Start and Stop around Runtime.Run.
+type Profiler struct {
+	functions map[*Function]*FunctionProfile
+	script    *FunctionProfile
+	lines     map[LineProfile]int
+	stack     []*profileCall
+	// active is the number of calls of the function on the stack, to count recursive time once
+	active map[*FunctionProfile]int
+	// samples is self time and return count per call stack
+	samples  map[string]*stackSample
+	started  time.Time
+	duration time.Duration
+}
+
+// stackSample is a sample of pprof format
+type stackSample struct {
+	stack []*FunctionProfile
+	calls int64
+	self  time.Duration
+}
+
+// NewProfiler is constructor of Profiler
+func NewProfiler() *Profiler {
+	return &Profiler{
+		functions: make(map[*Function]*FunctionProfile),
+		script:    &FunctionProfile{Name: scriptName},
+		lines:     make(map[LineProfile]int),
+		stack:     make([]*profileCall, 0),
+		active:    make(map[*FunctionProfile]int),
+		samples:   make(map[string]*stackSample),
+	}
+}
+
+// Start starts measuring top-level code
+func (p *Profiler) Start() {
+	p.started = time.Now()
+	p.push(p.script)
+}
+
+// Stop stops measuring. Functions which haven't returned yet, e.g. because of runtime error, are closed.
+func (p *Profiler) Stop() {
+	for len(p.stack) > 0 {
+		p.pop()
+	}
+	p.duration = time.Now().Sub(p.started)
+}
+
+// Statement counts execution of the line
+func (p *Profiler) Statement(i *Interpreter, stmt Stmt) error {
+	pos, ok := i.Runtime.Positions[stmt]
+	if !ok {
+		return nil
+	}
+	if len(p.stack) > 0 {
+		call := p.stack[len(p.stack)-1]
+		nested := call.last != nil && nestedOnSameLine(call.last, call.lastPos, pos)
+		call.last, call.lastPos = stmt, pos
+		if nested {
+			return nil
+		}
+	}
+	// a block is counted by its statements
+	if _, ok := i.Runtime.ForLoops[stmt]; !ok && stmt.IsType(&Block{}) {
+		return nil
+	}
+	p.lines[LineProfile{File: pos.File, Line: pos.Line}]++
+	return nil
+}
+
+// Call starts measuring the function
+func (p *Profiler) Call(i *Interpreter, function *GoLoxFunction) {
+	declaration := function.Declaration()
+	profile, ok := p.functions[declaration]
+	if !ok {
+		profile = &FunctionProfile{Name: function.Name()}
+		if declaration.Name != nil {
+			profile.Line = declaration.Name.Line
+		}
+		if pos, ok := i.Runtime.Positions[declaration]; ok {
+			profile.File = pos.File
+			// lambdas have no name
+			if profile.Line == 0 {
+				profile.Line = pos.Line
+			}
+		}
+		p.functions[declaration] = profile
+	}
+	p.push(profile)
+}
+
+// Return stops measuring the function
+func (p *Profiler) Return(i *Interpreter, function *GoLoxFunction) {
+	p.pop()
+}
+
+func (p *Profiler) push(profile *FunctionProfile) {
+	p.stack = append(p.stack, &profileCall{profile: profile, start: time.Now()})
+	p.active[profile]++
+}
+
+func (p *Profiler) pop() {
+	call := p.stack[len(p.stack)-1]
+	elapsed := time.Now().Sub(call.start)
+	self := elapsed - call.children
+
+	profile := call.profile
+	profile.Calls++
+	profile.Self += self
+	p.active[profile]--
+	if p.active[profile] == 0 {
+		profile.Cumulative += elapsed
+	}
+
+	stack := make([]*FunctionProfile, 0, len(p.stack))
+	keys := make([]string, 0, len(p.stack))
+	for k := len(p.stack) - 1; k >= 0; k-- {
+		stack = append(stack, p.stack[k].profile)
+		keys = append(keys, fmt.Sprintf("%p", p.stack[k].profile))
+	}
+	key := strings.Join(keys, ";")
+	sample, ok := p.samples[key]
+	if !ok {
+		sample = &stackSample{stack: stack}
+		p.samples[key] = sample
+	}
+	sample.calls++
+	sample.self += self
+
+	p.stack = p.stack[:len(p.stack)-1]
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += elapsed
+	}
+}
+
+// Functions returns profiles of called functions and top-level code sorted by self time
+func (p *Profiler) Functions() []*FunctionProfile {
+	profiles := make([]*FunctionProfile, 0, len(p.functions)+1)
+	if p.script.Calls > 0 {
+		profiles = append(profiles, p.script)
+	}
+	for _, profile := range p.functions {
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		a, b := profiles[i], profiles[j]
+		if a.Self != b.Self {
+			return a.Self > b.Self
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return profiles
+}
+
+// Lines returns execution counts of lines sorted by file and line
+func (p *Profiler) Lines() []*LineProfile {
+	lines := make([]*LineProfile, 0, len(p.lines))
+	for line, count := range p.lines {
+		lines = append(lines, &LineProfile{File: line.File, Line: line.Line, Count: count})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].File != lines[j].File {
+			return lines[i].File < lines[j].File
+		}
+		return lines[i].Line < lines[j].Line
+	})
+	return lines
+}
+
+// WriteText writes human readable report
+func (p *Profiler) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total: %.3fms\n\n", milliseconds(p.duration))
+	fmt.Fprintf(&b, "%8s %12s %12s  %s\n", "calls", "cum(ms)", "self(ms)", "function")
+	for _, profile := range p.Functions() {
+		fmt.Fprintf(&b, "%8d %12.3f %12.3f  %s\n", profile.Calls, milliseconds(profile.Cumulative), milliseconds(profile.Self), profile.location())
+	}
+
+	fmt.Fprintf(&b, "\n%8s  %s\n", "count", "line")
+	for _, line := range p.Lines() {
+		fmt.Fprintf(&b, "%8d  %s:%d\n", line.Count, displayFile(line.File), line.Line)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (fp *FunctionProfile) location() string {
+	if fp.Line == 0 {
+		return fp.Name
+	}
+	return fmt.Sprintf("%s (%s:%d)", fp.Name, displayFile(fp.File), fp.Line)
+}
+
+func displayFile(file string) string {
+	if file == "" {
+		return "<stdin>"
+	}
+	return filepath.ToSlash(file)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
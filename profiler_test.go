@@ -0,0 +1,161 @@
+package golox_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+const profilee = `fun fib(n) {
+  if (n < 2) return n;
+  return fib(n - 1) + fib(n - 2);
+}
+class Counter {
+  init() { this.n = 0; }
+  inc() { this.n = this.n + 1; }
+}
+var c = Counter();
+for (var i = 0; i < 3; i = i + 1) {
+  c.inc();
+}
+print fib(5);
+`
+
+func profile(source string) *golox.Profiler {
+	r := golox.NewRuntime()
+	r.Stdout = &bytes.Buffer{}
+	r.File = "/tmp/profilee.lox"
+
+	profiler := golox.NewProfiler()
+	r.AddTracer(profiler)
+	profiler.Start()
+	r.Run(bytes.NewBufferString(source))
+	profiler.Stop()
+
+	return profiler
+}
+
+func TestProfiler_Functions(t *testing.T) {
+	profiler := profile(profilee)
+
+	calls := make(map[string]int)
+	profiles := make(map[string]*golox.FunctionProfile)
+	for _, fp := range profiler.Functions() {
+		calls[fp.Name] = fp.Calls
+		profiles[fp.Name] = fp
+		assert.True(t, fp.Self <= fp.Cumulative, fp.Name)
+	}
+
+	assert.Equal(t, map[string]int{"<script>": 1, "fib": 15, "init": 1, "inc": 3}, calls)
+	assert.Equal(t, "/tmp/profilee.lox", profiles["fib"].File)
+	assert.Equal(t, 1, profiles["fib"].Line)
+	assert.Equal(t, 7, profiles["inc"].Line)
+	// time of recursive calls is counted once
+	assert.True(t, profiles["fib"].Cumulative <= profiles["<script>"].Cumulative)
+}
+
+func TestProfiler_Lambda(t *testing.T) {
+	profiler := profile("var f = (x) => x;\nvar g = fun () {\n  return f(1);\n};\ng();\n")
+
+	profiles := make(map[int]*golox.FunctionProfile)
+	for _, fp := range profiler.Functions() {
+		profiles[fp.Line] = fp
+	}
+	assert.Equal(t, "<lambda>", profiles[1].Name)
+	assert.Equal(t, "<lambda>", profiles[2].Name)
+	assert.Equal(t, 1, profiles[2].Calls)
+}
+
+func TestProfiler_Lines(t *testing.T) {
+	profiler := profile(profilee)
+
+	counts := make(map[int]int)
+	for _, line := range profiler.Lines() {
+		assert.Equal(t, "/tmp/profilee.lox", line.File)
+		counts[line.Line] = line.Count
+	}
+
+	expected := map[int]int{
+		1:  1,  // fun fib
+		2:  15, // if (n < 2) return n; is counted once per call
+		3:  7,
+		5:  1,
+		6:  1,
+		7:  3,
+		9:  1,
+		10: 1,
+		11: 3,
+		13: 1,
+	}
+	assert.Equal(t, expected, counts)
+}
+
+func TestProfiler_WriteText(t *testing.T) {
+	profiler := profile(profilee)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, profiler.WriteText(buf))
+	report := buf.String()
+	assert.Contains(t, report, "calls      cum(ms)     self(ms)  function\n")
+	assert.Regexp(t, `\n +15 +[0-9.]+ +[0-9.]+  fib \(/tmp/profilee.lox:1\)\n`, report)
+	assert.Regexp(t, `\n +1 +[0-9.]+ +[0-9.]+  <script>\n`, report)
+	assert.Contains(t, report, "\n      15  /tmp/profilee.lox:2\n")
+}
+
+func TestProfiler_WritePprof(t *testing.T) {
+	profiler := profile(profilee)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, profiler.WritePprof(buf))
+	gz, err := gzip.NewReader(buf)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(gz)
+	assert.NoError(t, err)
+
+	fields := make(map[uint64][][]byte)
+	for len(data) > 0 {
+		key, n := readVarint(data)
+		data = data[n:]
+		switch key & 7 {
+		case 0:
+			_, n = readVarint(data)
+			data = data[n:]
+			fields[key>>3] = append(fields[key>>3], nil)
+		case 2:
+			length, n := readVarint(data)
+			data = data[n:]
+			fields[key>>3] = append(fields[key>>3], data[:length])
+			data = data[length:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+
+	strings := make([]string, 0)
+	for _, s := range fields[6] {
+		strings = append(strings, string(s))
+	}
+	assert.Equal(t, "", strings[0])
+	assert.Subset(t, strings, []string{"calls", "count", "time", "nanoseconds", "script", "fib", "init", "inc", "/tmp/profilee.lox"})
+
+	assert.Equal(t, 2, len(fields[1]), "sample types")
+	assert.Equal(t, 4, len(fields[5]), "functions")
+	assert.Equal(t, 4, len(fields[4]), "locations")
+	// script, init, inc and fib(5) whose recursion is 5 levels deep
+	assert.Equal(t, 8, len(fields[2]), "samples")
+}
+
+func readVarint(data []byte) (uint64, int) {
+	var x uint64
+	for n, b := range data {
+		x |= uint64(b&0x7f) << (7 * uint(n))
+		if b < 0x80 {
+			return x, n + 1
+		}
+	}
+	return x, len(data)
+}
@@ -1,8 +1,6 @@
 package golox
 
-import (
-	"errors"
-)
+import "sort"
 
 // Resolver is struct of resolver
 type Resolver struct {
@@ -10,6 +8,19 @@ type Resolver struct {
 	Interpreter     *Interpreter
 	currentFunction FunctionType
 	currentClass    ClassType
+	declarations    []map[string]*Token
+	globals         map[string]*Token
+	bindings        map[*Token]*Token
+	report          func(token *Token, message string)
+
+	// kinds is kind of each local declaration such as "variable" and "parameter"
+	kinds map[*Token]string
+	// shadows is local declarations which hide a local variable of an enclosing scope
+	shadows map[*Token]bool
+	// assignments is identifier tokens of assignment targets
+	assignments map[*Token]bool
+	// unreachable is statements which follow a statement never completing normally
+	unreachable []Stmt
 }
 
 // FunctionType is current scope function type
@@ -30,13 +41,22 @@ const (
 	SubClassCT
 )
 
-// NewResolver is constructor of Resolver
+// NewResolver is constructor of Resolver.
+// interpreter may be nil when the resolver is used only for static analysis.
 func NewResolver(runtime *Runtime, interpreter *Interpreter) *Resolver {
 	return &Resolver{
 		runtime:         runtime,
 		Interpreter:     interpreter,
 		currentFunction: NoneFT,
 		currentClass:    NoneCT,
+		declarations:    make([]map[string]*Token, 0),
+		globals:         make(map[string]*Token),
+		bindings:        make(map[*Token]*Token),
+		report:          runtime.ErrorTokenMessage,
+		kinds:           make(map[*Token]string),
+		shadows:         make(map[*Token]bool),
+		assignments:     make(map[*Token]bool),
+		unreachable:     make([]Stmt, 0),
 	}
 }
 
@@ -47,28 +67,37 @@ func (r *Resolver) visitBlockStmt(stmt *Block) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitCaseStmt(stmt *Case) (interface{}, error) {
+	for _, value := range stmt.Values {
+		_, err := r.resolveExpr(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.beginScope()
+	r.ResolveStmts(stmt.Body)
+	r.endScope()
+	return nil, nil
+}
+
 func (r *Resolver) visitClassStmt(stmt *Class) (interface{}, error) {
 	enclosigClass := r.currentClass
 	r.currentClass = ClassCT
 
-	r.declare(stmt.Name)
+	r.declare(stmt.Name, "class")
 	r.define(stmt.Name)
 
-	if stmt.Superclass != nil {
-		_, err := r.resolveExpr(stmt.Superclass)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// ex. class Hoge(Hoge):
+	// ex. class Hoge < Hoge {}
 	if stmt.Superclass != nil && stmt.Name.Lexeme == stmt.Superclass.Name.Lexeme {
-		r.runtime.ErrorTokenMessage(stmt.Superclass.Name, "A class can't inherit from itself.")
+		r.report(stmt.Superclass.Name, "A class can't inherit from itself.")
 	}
 
 	if stmt.Superclass != nil {
 		r.currentClass = SubClassCT
-		r.resolveExpr(stmt.Superclass)
+		_, err := r.resolveExpr(stmt.Superclass)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if stmt.Superclass != nil {
@@ -102,7 +131,7 @@ func (r *Resolver) visitExpressionStmt(stmt *Expression) (interface{}, error) {
 }
 
 func (r *Resolver) visitFunctionStmt(stmt *Function) (interface{}, error) {
-	r.declare(stmt.Name)
+	r.declare(stmt.Name, "function")
 	r.define(stmt.Name)
 
 	r.resolveFunction(stmt, FunctionFT)
@@ -138,12 +167,12 @@ func (r *Resolver) visitPrintStmt(stmt *Print) (interface{}, error) {
 
 func (r *Resolver) visitReturnStmt(stmt *Return) (interface{}, error) {
 	if r.currentFunction == NoneFT {
-		r.runtime.ErrorTokenMessage(stmt.Keyword, "Can't return from top-level code.")
+		r.report(stmt.Keyword, "Can't return from top-level code.")
 	}
 
 	if stmt.Value != nil {
 		if r.currentFunction == InitializerFT {
-			r.runtime.ErrorTokenMessage(stmt.Keyword, "Can't return a value from an initializer.")
+			r.report(stmt.Keyword, "Can't return a value from an initializer.")
 		}
 
 		_, err := r.resolveExpr(stmt.Value)
@@ -168,18 +197,40 @@ func (r *Resolver) visitWhileStmt(stmt *While) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitSwitchStmt(stmt *Switch) (interface{}, error) {
+	_, err := r.resolveExpr(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range stmt.Cases {
+		_, err := r.resolveStmt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
 func (r *Resolver) visitVarStmt(stmt *Var) (interface{}, error) {
-	r.declare(stmt.Name)
+	r.declare(stmt.Name, "variable")
 	if stmt.Initializer != nil {
-		r.resolveExpr(stmt.Initializer)
+		_, err := r.resolveExpr(stmt.Initializer)
+		if err != nil {
+			return nil, err
+		}
 	}
 	r.define(stmt.Name)
 	return nil, nil
 }
 
 func (r *Resolver) visitAssignExpr(expr *Assign) (interface{}, error) {
-	r.resolveExpr(expr.Value)
-	return nil, r.resolveLocal(expr, expr.Name)
+	_, err := r.resolveExpr(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	r.assignments[expr.Name] = true
+	r.resolveLocal(expr, expr.Name)
+	return nil, nil
 }
 
 func (r *Resolver) visitBinaryExpr(expr *Binary) (interface{}, error) {
@@ -212,6 +263,34 @@ func (r *Resolver) visitCallExpr(expr *Call) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitCompoundAssignExpr(expr *CompoundAssign) (interface{}, error) {
+	_, err := r.resolveExpr(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.resolveExpr(expr.Target)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) visitConditionalExpr(expr *Conditional) (interface{}, error) {
+	_, err := r.resolveExpr(expr.Condition)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.resolveExpr(expr.ThenBranch)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.resolveExpr(expr.ElseBranch)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
 func (r *Resolver) visitGetExpr(expr *Get) (interface{}, error) {
 	_, err := r.resolveExpr(expr.Object)
 	if err != nil {
@@ -228,10 +307,42 @@ func (r *Resolver) visitGroupingExpr(expr *Grouping) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitIndexExpr(expr *Index) (interface{}, error) {
+	_, err := r.resolveExpr(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.resolveExpr(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) visitInterpolationExpr(expr *Interpolation) (interface{}, error) {
+	for _, part := range expr.Parts {
+		_, err := r.resolveExpr(part)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
 func (r *Resolver) visitLiteralExpr(expr *Literal) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitListExpr(expr *List) (interface{}, error) {
+	for _, element := range expr.Elements {
+		_, err := r.resolveExpr(element)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
 func (r *Resolver) visitLogicalExpr(expr *Logical) (interface{}, error) {
 	_, err := r.resolveExpr(expr.Left)
 	if err != nil {
@@ -244,6 +355,43 @@ func (r *Resolver) visitLogicalExpr(expr *Logical) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitLambdaExpr(expr *Lambda) (interface{}, error) {
+	return r.resolveFunction(expr.Function, FunctionFT)
+}
+
+func (r *Resolver) visitMatchExpr(expr *Match) (interface{}, error) {
+	_, err := r.resolveExpr(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arm := range expr.Arms {
+		// classes are looked up outside of the scope of the arm
+		for _, class := range patternClasses(arm.Pattern) {
+			_, err := r.resolveExpr(class)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		r.beginScope()
+		for _, name := range patternBindings(arm.Pattern) {
+			r.declare(name, "variable")
+			r.define(name)
+		}
+		_, err := r.resolveExpr(arm.Body)
+		r.endScope()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) visitOptionalChainExpr(expr *OptionalChain) (interface{}, error) {
+	return r.resolveExpr(expr.Expression)
+}
+
 func (r *Resolver) visitSetExpr(expr *Set) (interface{}, error) {
 	_, err := r.resolveExpr(expr.Value)
 	if err != nil {
@@ -256,11 +404,27 @@ func (r *Resolver) visitSetExpr(expr *Set) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) visitSetIndexExpr(expr *SetIndex) (interface{}, error) {
+	_, err := r.resolveExpr(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.resolveExpr(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.resolveExpr(expr.Index)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
 func (r *Resolver) visitSuperExpr(expr *Super) (interface{}, error) {
 	if r.currentClass == NoneCT {
-		r.runtime.ErrorTokenMessage(expr.Keyword, "Can't use 'super' outside of a class.")
+		r.report(expr.Keyword, "Can't use 'super' outside of a class.")
 	} else if r.currentClass != SubClassCT {
-		r.runtime.ErrorTokenMessage(expr.Keyword, "Can't use 'super' in a class with no superclass.")
+		r.report(expr.Keyword, "Can't use 'super' in a class with no superclass.")
 	}
 
 	r.resolveLocal(expr, expr.Keyword)
@@ -269,7 +433,7 @@ func (r *Resolver) visitSuperExpr(expr *Super) (interface{}, error) {
 
 func (r *Resolver) visitThisExpr(expr *This) (interface{}, error) {
 	if r.currentClass == NoneCT {
-		r.runtime.ErrorTokenMessage(expr.Keyword, "Can't use 'this' outside of a class.")
+		r.report(expr.Keyword, "Can't use 'this' outside of a class.")
 		return nil, nil
 	}
 
@@ -278,14 +442,13 @@ func (r *Resolver) visitThisExpr(expr *This) (interface{}, error) {
 }
 
 func (r *Resolver) visitUnaryExpr(expr *Unary) (interface{}, error) {
-	r.resolveExpr(expr.Right)
-	return nil, nil
+	return r.resolveExpr(expr.Right)
 }
 
 func (r *Resolver) visitVariableExpr(expr *Variable) (interface{}, error) {
 	if !r.runtime.Scopes.IsEmpty() {
 		if v, ok := r.runtime.Scopes.Peek()[expr.Name.Lexeme]; !v && ok { // declare variable && not define
-			r.runtime.ErrorTokenMessage(expr.Name, "Can't read local variable in its own initializer.")
+			r.report(expr.Name, "Can't read local variable in its own initializer.")
 		}
 	}
 
@@ -295,11 +458,19 @@ func (r *Resolver) visitVariableExpr(expr *Variable) (interface{}, error) {
 
 // ResolveStmts resolves statements
 func (r *Resolver) ResolveStmts(stmts []Stmt) (interface{}, error) {
+	reachable := true
 	for _, stmt := range stmts {
+		if !reachable {
+			r.unreachable = append(r.unreachable, stmt)
+			reachable = true
+		}
 		_, err := r.resolveStmt(stmt)
 		if err != nil {
 			return nil, err
 		}
+		if terminates(stmt) {
+			reachable = false
+		}
 	}
 
 	return nil, nil
@@ -318,34 +489,42 @@ func (r *Resolver) resolveFunction(function *Function, typ FunctionType) (interf
 	r.currentFunction = typ
 	r.beginScope()
 	for _, param := range function.Params {
-		r.declare(param)
+		r.declare(param, "parameter")
 		r.define(param)
 	}
 	_, err := r.ResolveStmts(function.Body)
-	if err != nil {
-		return nil, err
-	}
 	r.endScope()
 	r.currentFunction = enclosingFunction
-	return nil, nil
+	return nil, err
 }
 
 func (r *Resolver) beginScope() {
 	r.runtime.Scopes.Push(make(map[string]bool))
+	r.declarations = append(r.declarations, make(map[string]*Token))
 }
 
 func (r *Resolver) endScope() {
 	r.runtime.Scopes.Pop()
+	r.declarations = r.declarations[:len(r.declarations)-1]
 }
 
-func (r *Resolver) declare(name *Token) {
+func (r *Resolver) declare(name *Token, kind string) {
+	r.bindings[name] = name
 	if r.runtime.Scopes.IsEmpty() {
+		r.globals[name.Lexeme] = name
 		return
 	}
+	r.kinds[name] = kind
+	for _, declarations := range r.declarations[:len(r.declarations)-1] {
+		if _, ok := declarations[name.Lexeme]; ok {
+			r.shadows[name] = true
+		}
+	}
+	r.declarations[len(r.declarations)-1][name.Lexeme] = name
 
 	scope := r.runtime.Scopes.Peek()
 	if _, ok := scope[name.Lexeme]; ok {
-		r.runtime.ErrorTokenMessage(name, "Already a variable with this name in this scope.")
+		r.report(name, "Already a variable with this name in this scope.")
 	}
 
 	scope[name.Lexeme] = false
@@ -360,17 +539,82 @@ func (r *Resolver) define(name *Token) {
 	r.runtime.Scopes.Peek()[name.Lexeme] = true
 }
 
-func (r *Resolver) resolveLocal(expr Expr, name *Token) error {
+// resolveLocal records the depth of scope which the variable is declared in.
+// If the variable is not found, it is assumed to be global.
+func (r *Resolver) resolveLocal(expr Expr, name *Token) {
 	for i := 0; i < r.runtime.Scopes.Size(); i++ {
-		scope, err := r.runtime.Scopes.Get(i)
-		if err != nil {
-			return err
-		}
+		scope, _ := r.runtime.Scopes.Get(i)
 		if _, ok := scope[name.Lexeme]; ok {
-			r.Interpreter.Resolve(expr, i)
-			return nil
+			if r.Interpreter != nil {
+				r.Interpreter.Resolve(expr, i)
+			}
+			if name.Type == IdentifierTT {
+				r.bindings[name] = r.declarations[len(r.declarations)-1-i][name.Lexeme]
+			}
+			return
+		}
+	}
+
+	if name.Type == IdentifierTT {
+		r.bindings[name] = nil
+	}
+}
+
+// Definition returns the token which declares the variable referred by name.
+// name is an identifier token of declaration, variable or assignment.
+func (r *Resolver) Definition(name *Token) (*Token, bool) {
+	decl, ok := r.bindings[name]
+	if !ok {
+		return nil, false
+	}
+	if decl == nil {
+		// global variable is looked up after resolving whole statements
+		decl, ok = r.globals[name.Lexeme]
+	}
+	return decl, ok
+}
+
+// References returns tokens which refer to the variable declared by decl including decl itself.
+// The tokens are sorted by the position.
+func (r *Resolver) References(decl *Token) []*Token {
+	tokens := make([]*Token, 0)
+	for name := range r.bindings {
+		if d, ok := r.Definition(name); ok && d == decl {
+			tokens = append(tokens, name)
 		}
 	}
 
-	return errors.New("no variable")
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Line != tokens[j].Line {
+			return tokens[i].Line < tokens[j].Line
+		}
+		return tokens[i].Column < tokens[j].Column
+	})
+	return tokens
+}
+
+// terminates reports whether control never reaches the statement after stmt.
+func terminates(stmt Stmt) bool {
+	switch s := stmt.(type) {
+	case *Return:
+		return true
+	case *Block:
+		for _, v := range s.Statements {
+			if terminates(v) {
+				return true
+			}
+		}
+	case *If:
+		return s.ElseBranch != nil && terminates(s.ThenBranch) && terminates(s.ElseBranch)
+	case *Switch:
+		hasDefault := false
+		for _, c := range s.Cases {
+			if !terminates(NewBlock(c.Body)) {
+				return false
+			}
+			hasDefault = hasDefault || c.Values == nil
+		}
+		return hasDefault
+	}
+	return false
 }
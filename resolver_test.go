@@ -0,0 +1,122 @@
+package golox_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		code     string
+	}{
+		{
+			name:     "read local variable in its own initializer",
+			expected: "[line 3] Error at 'a': Can't read local variable in its own initializer.\n",
+			code:     "var a = 1;\n{\n  var a = a;\n}",
+		},
+		{
+			name:     "own initializer after assigning global",
+			expected: "[line 4] Error at 'x': Can't read local variable in its own initializer.\n",
+			code:     "var g;\nfun f() {\n  g = 1;\n  var x = x;\n}",
+		},
+		{
+			name:     "already declared in the same scope",
+			expected: "[line 3] Error at 'a': Already a variable with this name in this scope.\n",
+			code:     "fun f() {\n  var a;\n  var a;\n}",
+		},
+		{
+			name:     "return at top level",
+			expected: "[line 1] Error at 'return': Can't return from top-level code.\n",
+			code:     "return 1;",
+		},
+		{
+			name:     "return value from initializer",
+			expected: "[line 3] Error at 'return': Can't return a value from an initializer.\n",
+			code:     "class A {\n  init() {\n    return 1;\n  }\n}",
+		},
+		{
+			name:     "super outside of class",
+			expected: "[line 1] Error at 'super': Can't use 'super' outside of a class.\n",
+			code:     "print super.f;",
+		},
+		{
+			name:     "super in class without superclass",
+			expected: "[line 3] Error at 'super': Can't use 'super' in a class with no superclass.\n",
+			code:     "class A {\n  f() {\n    super.f();\n  }\n}",
+		},
+		{
+			name:     "this outside of class",
+			expected: "[line 2] Error at 'this': Can't use 'this' outside of a class.\n",
+			code:     "fun f() {\n  return this;\n}",
+		},
+		{
+			name:     "inherit from itself",
+			expected: "[line 1] Error at 'A': A class can't inherit from itself.\n",
+			code:     "class A < A {}",
+		},
+		{
+			name:     "multiple errors",
+			expected: "[line 1] Error at 'return': Can't return from top-level code.\n[line 2] Error at 'this': Can't use 'this' outside of a class.\n",
+			code:     "return;\nprint this;",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stderr = stderr
+			stmts := r.Parse(bytes.NewBufferString(tt.code))
+			assert.False(t, r.HadError)
+
+			resolver := golox.NewResolver(r, golox.NewInterpreter(r))
+			resolver.ResolveStmts(stmts)
+			assert.True(t, r.HadError)
+			assert.Equal(t, tt.expected, stderr.String())
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stderr = stderr
+	stmts := r.Parse(bytes.NewBufferString("var a = 1;\nvar b = a;\nfun f(x) {\n  a = x;\n  { var b = a; }\n  return x;\n}\nclass A < B {\n  init() { return; }\n  f() { return super.f() + this.x; }\n}"))
+	assert.False(t, r.HadError)
+
+	resolver := golox.NewResolver(r, golox.NewInterpreter(r))
+	resolver.ResolveStmts(stmts)
+	assert.Equal(t, "", stderr.String())
+}
+
+func TestResolver_Definition(t *testing.T) {
+	r := golox.NewRuntime()
+	tokens := golox.NewScanner(r, bytes.NewBufferString("var a = 1;\nfun f(a) {\n  a = a + 1;\n  return a;\n}\nprint f(a);")).ScanTokens()
+	stmts, err := golox.NewParser(r, tokens).Parse()
+	assert.NoError(t, err)
+
+	resolver := golox.NewResolver(r, golox.NewInterpreter(r))
+	resolver.ResolveStmts(stmts)
+
+	global, param := tokens[1], tokens[8]
+	assert.Equal(t, "a", param.Lexeme)
+	assert.Equal(t, 2, param.Line)
+
+	for _, i := range []int{11, 13, 18} {
+		decl, ok := resolver.Definition(tokens[i])
+		assert.True(t, ok)
+		assert.Same(t, param, decl)
+	}
+	decl, ok := resolver.Definition(tokens[len(tokens)-4])
+	assert.True(t, ok)
+	assert.Same(t, global, decl)
+
+	assert.Equal(t, []*golox.Token{param, tokens[11], tokens[13], tokens[18]}, resolver.References(param))
+	assert.Equal(t, []*golox.Token{global, tokens[len(tokens)-4]}, resolver.References(global))
+}
@@ -3,7 +3,13 @@ package golox
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"os"
+	"time"
+
+	"github.com/goropikari/golox/native_function"
 )
 
 // Runtime is struct of Runtime
@@ -13,8 +19,17 @@ type Runtime struct {
 	Globals         *Environment
 	Environment     *Environment
 	Locals          map[Expr]int
+	Positions       map[Stmt]*Position
+	ForLoops        map[Stmt]*ForLoop
+	LiteralTokens   map[Expr]*Token
 	Scopes          *ScopeStack
 	BasePath        string
+	File            string
+	Tracers         []Tracer
+	Diagnostics     []*Diagnostic
+	Stdout          io.Writer
+	Stderr          io.Writer
+	Rand            *rand.Rand
 }
 
 // NewRuntime is constructor of Runtime
@@ -22,29 +37,82 @@ func NewRuntime() *Runtime {
 	globals := NewEnvironment(nil)
 	environment := globals
 
-	return &Runtime{
+	r := &Runtime{
 		HadError:        false,
 		HadRuntimeError: false,
 		Globals:         globals,
 		Environment:     environment,
 		Locals:          make(map[Expr]int),
+		Positions:       make(map[Stmt]*Position),
+		ForLoops:        make(map[Stmt]*ForLoop),
+		LiteralTokens:   make(map[Expr]*Token),
 		Scopes:          NewScopeStack(),
 		BasePath:        "",
+		File:            "",
+		Tracers:         make([]Tracer, 0),
+		Diagnostics:     make([]*Diagnostic, 0),
+		Stdout:          os.Stdout,
+		Stderr:          os.Stderr,
+		Rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	r.defineNatives()
+	return r
 }
 
-// Run runs script
-func (r *Runtime) Run(source *bytes.Buffer) {
+// defineNatives registers native functions and constants as globals.
+// They're defined once per runtime so that scripts and embedders can redefine them.
+func (r *Runtime) defineNatives() {
+	r.Globals.Define("clock", NewNativeFunction(native_function.NewClockFunc()))
+	r.Globals.Define("exit", NewNativeFunction(native_function.NewExitFunc()))
+	r.Globals.Define("str", NewNativeFunction(native_function.NewStrFunc()))
+	r.Globals.Define("toFixed", NewNativeFunction(native_function.NewToFixedFunc()))
+	r.Globals.Define("num", NewNativeFunction(native_function.NewNumFunc(parseNumber)))
+	r.Globals.Define("bool", NewNativeFunction(native_function.NewBoolFunc()))
+	r.Globals.Define("type", NewNativeFunction(native_function.NewTypeFunc()))
+	r.Globals.Define("instanceOf", NewNativeFunction(native_function.NewInstanceOfFunc()))
+	r.Globals.Define("sqrt", NewNativeFunction(native_function.NewSqrtFunc()))
+	r.Globals.Define("pow", NewNativeFunction(native_function.NewPowFunc()))
+	r.Globals.Define("floor", NewNativeFunction(native_function.NewFloorFunc()))
+	r.Globals.Define("ceil", NewNativeFunction(native_function.NewCeilFunc()))
+	r.Globals.Define("round", NewNativeFunction(native_function.NewRoundFunc()))
+	r.Globals.Define("abs", NewNativeFunction(native_function.NewAbsFunc()))
+	r.Globals.Define("min", NewNativeFunction(native_function.NewMinFunc()))
+	r.Globals.Define("max", NewNativeFunction(native_function.NewMaxFunc()))
+	r.Globals.Define("sin", NewNativeFunction(native_function.NewSinFunc()))
+	r.Globals.Define("cos", NewNativeFunction(native_function.NewCosFunc()))
+	r.Globals.Define("tan", NewNativeFunction(native_function.NewTanFunc()))
+	r.Globals.Define("asin", NewNativeFunction(native_function.NewAsinFunc()))
+	r.Globals.Define("acos", NewNativeFunction(native_function.NewAcosFunc()))
+	r.Globals.Define("atan", NewNativeFunction(native_function.NewAtanFunc()))
+	r.Globals.Define("atan2", NewNativeFunction(native_function.NewAtan2Func()))
+	r.Globals.Define("log", NewNativeFunction(native_function.NewLogFunc()))
+	r.Globals.Define("exp", NewNativeFunction(native_function.NewExpFunc()))
+	r.Globals.Define("random", NewNativeFunction(native_function.NewRandomFunc(r.Rand)))
+	r.Globals.Define("randomInt", NewNativeFunction(native_function.NewRandomIntFunc(r.Rand)))
+	r.Globals.Define("map", NewContextNativeFunction(NewMapFunc()))
+	r.Globals.Define("filter", NewContextNativeFunction(NewFilterFunc()))
+	r.Globals.Define("reduce", NewContextNativeFunction(NewReduceFunc()))
+	r.Globals.Define("forEach", NewContextNativeFunction(NewForEachFunc()))
+	r.Globals.Define("sort", NewContextNativeFunction(NewSortFunc()))
+	r.Globals.Define("range", NewContextNativeFunction(NewRangeFunc()))
+	r.Globals.Define("zip", NewContextNativeFunction(NewZipFunc()))
+	r.Globals.Define("enumerate", NewContextNativeFunction(NewEnumerateFunc()))
+	r.Globals.Define("any", NewContextNativeFunction(NewAnyFunc()))
+	r.Globals.Define("all", NewContextNativeFunction(NewAllFunc()))
+	r.Globals.Define("PI", math.Pi)
+	r.Globals.Define("E", math.E)
+	r.Globals.Define("INF", math.Inf(1))
+	r.Globals.Define("NAN", math.NaN())
+}
 
-	scanner := NewScanner(r, source)
-	tokens := scanner.ScanTokens()
-	// for _, token := range tokens {
-	// 	fmt.Println(token)
-	// }
+// Seed fixes seed of random and randomInt for reproducible runs
+func (r *Runtime) Seed(seed int64) {
+	r.Rand.Seed(seed)
+}
 
-	parser := NewParser(r, tokens)
-	statements, _ := parser.Parse()
-	// parser.Parse()
+// Run runs script
+func (r *Runtime) Run(source *bytes.Buffer) {
+	statements := r.Parse(source)
 
 	// Stop if there was a syntax error
 	if r.HadError {
@@ -65,13 +133,29 @@ func (r *Runtime) Run(source *bytes.Buffer) {
 	interpreter.Interpret(statements)
 }
 
+// Parse scans and parses script without running it
+func (r *Runtime) Parse(source *bytes.Buffer) []Stmt {
+	scanner := NewScanner(r, source)
+	tokens := scanner.ScanTokens()
+	// for _, token := range tokens {
+	// 	fmt.Println(token)
+	// }
+
+	parser := NewParser(r, tokens)
+	statements, _ := parser.Parse()
+
+	return statements
+}
+
 // ErrorMessage prints error massage at stderr
 func (r *Runtime) ErrorMessage(line int, message string) {
+	r.Diagnostics = append(r.Diagnostics, NewDiagnostic(line, 0, "", message))
 	r.report(line, "", message)
 }
 
 // ErrorTokenMessage prints error message at stderr
 func (r *Runtime) ErrorTokenMessage(token *Token, message string) {
+	r.Diagnostics = append(r.Diagnostics, NewDiagnostic(token.Line, token.Column, token.Lexeme, message))
 	if token.Type == EOFTT {
 		r.report(token.Line, " at end", message)
 	} else {
@@ -81,13 +165,13 @@ func (r *Runtime) ErrorTokenMessage(token *Token, message string) {
 
 // Report prints error masseg at stderr
 func (r *Runtime) report(line int, where string, message string) {
-	fmt.Fprintln(os.Stderr, "[line "+fmt.Sprint(line)+"] Error"+where+": "+message)
+	fmt.Fprintln(r.Stderr, "[line "+fmt.Sprint(line)+"] Error"+where+": "+message)
 	r.HadError = true
 }
 
-// RuntimeError is error of runtime
+// RuntimeError prints runtime error message at stderr in the same format as jlox
 func (r *Runtime) RuntimeError(err error) {
 	e := err.(*CustomError)
-	fmt.Fprint(os.Stderr, err.Error()+"\n[line "+fmt.Sprint(e.Token.Line)+"]")
+	fmt.Fprintln(r.Stderr, e.Message()+"\n[line "+fmt.Sprint(e.Token.Line)+"]")
 	r.HadRuntimeError = true
 }
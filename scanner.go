@@ -2,7 +2,10 @@ package golox
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"unicode"
 )
 
@@ -13,16 +16,25 @@ type Scanner struct {
 	source      *bytes.Buffer
 	sourceRunes []rune
 	tokens      TokenList
+	comments    TokenList
+	keepComment bool
 	start       int
 	current     int
 	line        int
+	lineStart   int
+	startLine   int
+	startColumn int
+	// interpolations holds the number of unclosed '{' in each ${...} being scanned
+	interpolations []int
 }
 
 // NewScanner is constructor of Scanner
 func NewScanner(r *Runtime, b *bytes.Buffer) *Scanner {
 	var keywords = map[string]TokenType{
 		"and":     AndTT,
+		"case":    CaseTT,
 		"class":   ClassTT,
+		"default": DefaultTT,
 		"else":    ElseTT,
 		"elseif":  ElseifTT,
 		"false":   FalseTT,
@@ -30,11 +42,13 @@ func NewScanner(r *Runtime, b *bytes.Buffer) *Scanner {
 		"fun":     FunTT,
 		"if":      IfTT,
 		"include": IncludeTT,
+		"match":   MatchTT,
 		"nil":     NilTT,
 		"or":      OrTT,
 		"print":   PrintTT,
 		"return":  ReturnTT,
 		"super":   SuperTT,
+		"switch":  SwitchTT,
 		"this":    ThisTT,
 		"true":    TrueTT,
 		"var":     VarTT,
@@ -47,6 +61,7 @@ func NewScanner(r *Runtime, b *bytes.Buffer) *Scanner {
 		source:      b,
 		sourceRunes: bytes.Runes(b.Bytes()),
 		tokens:      []*Token{},
+		comments:    []*Token{},
 		start:       0,
 		current:     0,
 		line:        1,
@@ -58,13 +73,25 @@ func (s *Scanner) ScanTokens() TokenList {
 	for !s.isAtEnd() {
 		s.addBlock()
 		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.current - s.lineStart + 1
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, NewToken(EOFTT, "", nil, s.line))
+	eof := NewToken(EOFTT, "", nil, s.line)
+	eof.Column = s.current - s.lineStart + 1
+	s.tokens = append(s.tokens, eof)
 	return s.tokens
 }
 
+// ScanTokensWithComments generates tokens and comments from given source code.
+// Comments are returned separately so that they don't disturb the parser.
+func (s *Scanner) ScanTokensWithComments() (TokenList, TokenList) {
+	s.keepComment = true
+	tokens := s.ScanTokens()
+	return tokens, s.comments
+}
+
 func (s *Scanner) scanToken() {
 	c, _, _ := s.advance()
 
@@ -76,28 +103,107 @@ func (s *Scanner) scanToken() {
 		s.addToken(RightParenTT, nil)
 		break
 	case '{':
+		if n := len(s.interpolations); n > 0 {
+			s.interpolations[n-1]++
+		}
 		s.addToken(LeftBraceTT, nil)
 		break
 	case '}':
+		if n := len(s.interpolations); n > 0 {
+			if s.interpolations[n-1] == 0 {
+				// The end of ${...}. The rest of the string follows.
+				s.interpolations = s.interpolations[:n-1]
+				if last := len(s.tokens) - 1; s.tokens[last].Type == InterpolationTT {
+					s.runtime.ErrorMessage(s.line, "Expect expression in string interpolation.")
+					s.mergeEmptyInterpolation()
+					break
+				}
+				s.addString()
+				break
+			}
+			s.interpolations[n-1]--
+		}
 		s.addToken(RightBraceTT, nil)
 		break
+	case '[':
+		s.addToken(LeftBracketTT, nil)
+		break
+	case ']':
+		s.addToken(RightBracketTT, nil)
+		break
 	case ',':
 		s.addToken(CommaTT, nil)
 		break
 	case '.':
+		if unicode.IsDigit(s.peek()) {
+			// scan it as a number to continue parsing
+			s.runtime.ErrorMessage(s.line, "Number literal can't start with '.'; add a leading '0'.")
+			s.addNumber()
+			break
+		}
 		s.addToken(DotTT, nil)
 		break
 	case '-':
-		s.addToken(MinusTT, nil)
+		if s.match('=') {
+			s.addToken(MinusEqualTT, nil)
+		} else if s.peek() == '-' && s.isDecrement() {
+			s.advance()
+			s.addToken(MinusMinusTT, nil)
+		} else {
+			s.addToken(MinusTT, nil)
+		}
 		break
 	case '+':
-		s.addToken(PlusTT, nil)
+		if s.match('=') {
+			s.addToken(PlusEqualTT, nil)
+		} else if s.match('+') {
+			s.addToken(PlusPlusTT, nil)
+		} else {
+			s.addToken(PlusTT, nil)
+		}
 		break
 	case ';':
 		s.addToken(SemicolonTT, nil)
 		break
 	case '*':
-		s.addToken(StarTT, nil)
+		if s.match('*') {
+			s.addToken(StarStarTT, nil)
+		} else if s.match('=') {
+			s.addToken(StarEqualTT, nil)
+		} else {
+			s.addToken(StarTT, nil)
+		}
+		break
+	case '%':
+		s.addToken(PercentTT, nil)
+		break
+	case '?':
+		if s.match('?') {
+			s.addToken(QuestionQuestionTT, nil)
+		} else if s.match('.') {
+			s.addToken(QuestionDotTT, nil)
+		} else {
+			s.addToken(QuestionTT, nil)
+		}
+		break
+	case ':':
+		s.addToken(ColonTT, nil)
+		break
+	case '&':
+		s.addToken(AmpersandTT, nil)
+		break
+	case '|':
+		s.addToken(PipeTT, nil)
+		break
+	case '^':
+		s.addToken(CaretTT, nil)
+		break
+	case '~':
+		if s.match('/') {
+			s.addToken(TildeSlashTT, nil)
+		} else {
+			s.addToken(TildeTT, nil)
+		}
 		break
 	case '!':
 		var tt TokenType
@@ -111,6 +217,8 @@ func (s *Scanner) scanToken() {
 		var tt TokenType
 		if s.match('=') {
 			tt = EqualEqualTT
+		} else if s.match('>') {
+			tt = ArrowTT
 		} else {
 			tt = EqualTT
 		}
@@ -119,6 +227,8 @@ func (s *Scanner) scanToken() {
 		var tt TokenType
 		if s.match('=') {
 			tt = LessEqualTT
+		} else if s.match('<') {
+			tt = LessLessTT
 		} else {
 			tt = LessTT
 		}
@@ -127,6 +237,8 @@ func (s *Scanner) scanToken() {
 		var tt TokenType
 		if s.match('=') {
 			tt = GreaterEqualTT
+		} else if s.match('>') {
+			tt = GreaterGreaterTT
 		} else {
 			tt = GreaterTT
 		}
@@ -137,6 +249,11 @@ func (s *Scanner) scanToken() {
 			for s.peek() != '\n' && !s.isAtEnd() {
 				s.advance()
 			}
+			if s.keepComment {
+				s.addComment()
+			}
+		} else if s.match('=') {
+			s.addToken(SlashEqualTT, nil)
 		} else {
 			s.addToken(SlashTT, nil)
 		}
@@ -146,14 +263,18 @@ func (s *Scanner) scanToken() {
 	case '\t':
 		break
 	case '\n':
+		s.newLine()
 		break
 	case '"':
 		s.addString()
 		break
+	case '`':
+		s.addRawString()
+		break
 	default:
 		if unicode.IsDigit(c) {
 			s.addNumber()
-		} else if unicode.IsLetter(c) {
+		} else if unicode.IsLetter(c) || c == '_' {
 			s.addIdentifier()
 		} else {
 			s.runtime.ErrorMessage(s.line, "Unexpected character.")
@@ -184,7 +305,21 @@ func (s *Scanner) advance() (rune, int, error) {
 
 func (s *Scanner) addToken(tt TokenType, literal interface{}) {
 	text := string(s.sourceRunes[s.start:s.current])
-	s.tokens = append(s.tokens, NewToken(tt, text, literal, s.line))
+	token := NewToken(tt, text, literal, s.startLine)
+	token.Column = s.startColumn
+	s.tokens = append(s.tokens, token)
+}
+
+func (s *Scanner) addComment() {
+	text := string(s.sourceRunes[s.start:s.current])
+	comment := NewToken(CommentTT, text, nil, s.startLine)
+	comment.Column = s.startColumn
+	s.comments = append(s.comments, comment)
+}
+
+func (s *Scanner) newLine() {
+	s.line++
+	s.lineStart = s.current
 }
 
 func (s *Scanner) addBlock() {
@@ -194,18 +329,29 @@ func (s *Scanner) addBlock() {
 	}
 }
 
+// addString adds string literal or its part.
+// If the string has ${...}, the part before it is added as InterpolationTT and
+// the scanner goes back to scan the expression. The part after '}' is scanned by addString again.
 func (s *Scanner) addString() {
 	isEscape := false // define isEscape to handle \"
 	for (isEscape || s.peek() != '"') && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+		if !isEscape && s.peek() == '$' && s.peekNext() == '{' {
+			// Consume the "${"
+			s.advance()
+			s.advance()
+			s.interpolations = append(s.interpolations, 0)
+			s.addToken(InterpolationTT, s.unescape(s.sourceRunes[s.start+1:s.current-2]))
+			return
 		}
 		if s.peek() == '\\' {
 			isEscape = !isEscape
 		} else {
 			isEscape = false
 		}
-		s.advance()
+		c, _, _ := s.advance()
+		if c == '\n' {
+			s.newLine()
+		}
 	}
 
 	if s.isAtEnd() {
@@ -216,30 +362,210 @@ func (s *Scanner) addString() {
 	// The closing "
 	s.advance()
 
-	// value := string(s.sourceRunes[s.start+1 : s.current-1])
-	value := s.handleEscapeCharacter()
-	s.addToken(StringTT, string(value))
+	s.addToken(StringTT, s.unescape(s.sourceRunes[s.start+1:s.current-1]))
 }
 
-func (s *Scanner) addNumber() {
-	for unicode.IsDigit(s.peek()) {
-		s.advance()
+// mergeEmptyInterpolation scans the rest of the string after the empty ${} and joins it to the part before,
+// so that the parser doesn't report it again.
+func (s *Scanner) mergeEmptyInterpolation() {
+	last := len(s.tokens) - 1
+	before := s.tokens[last]
+	s.tokens = s.tokens[:last]
+	s.addString()
+	if len(s.tokens) == last {
+		// unterminated string
+		return
+	}
+
+	rest := s.tokens[last]
+	rest.Lexeme = before.Lexeme + rest.Lexeme
+	rest.Literal = before.Literal.(string) + rest.Literal.(string)
+	rest.Line, rest.Column = before.Line, before.Column
+}
+
+// addRawString adds string enclosed in backquotes. It may span lines and backslashes are kept literally.
+func (s *Scanner) addRawString() {
+	for s.peek() != '`' && !s.isAtEnd() {
+		c, _, _ := s.advance()
+		if c == '\n' {
+			s.newLine()
+		}
 	}
 
-	// Look for a fractional part.
-	if s.peek() == '.' && unicode.IsDigit(s.peekNext()) {
-		// Consume the "."
+	if s.isAtEnd() {
+		s.runtime.ErrorMessage(s.line, "Unterminated raw string.")
+		return
+	}
+
+	// The closing `
+	s.advance()
+
+	s.addToken(StringTT, string(s.sourceRunes[s.start+1:s.current-1]))
+}
+
+func (s *Scanner) addNumber() {
+	if s.sourceRunes[s.start] == '0' && radix(s.peek()) != 0 {
+		// Consume the prefix
 		s.advance()
+		s.skipNumberTail()
+	} else {
+		s.skipDigits()
 
-		for unicode.IsDigit(s.peek()) {
+		// Look for a fractional part.
+		if s.peek() == '.' && unicode.IsDigit(s.peekNext()) {
+			// Consume the "."
 			s.advance()
+			s.skipDigits()
+		}
+
+		// Look for an exponent part.
+		if s.peek() == 'e' || s.peek() == 'E' {
+			next := s.peekNext()
+			if next == '+' || next == '-' {
+				next = s.peekAt(2)
+			}
+			if unicode.IsDigit(next) {
+				// Consume the "e" and the sign
+				s.advance()
+				if s.peek() == '+' || s.peek() == '-' {
+					s.advance()
+				}
+				s.skipDigits()
+			}
 		}
+		s.skipNumberTail()
 	}
 
-	f, _ := strconv.ParseFloat(string(s.sourceRunes[s.start:s.current]), 64)
+	text := string(s.sourceRunes[s.start:s.current])
+	if strings.HasPrefix(text, ".") {
+		// leading dot is already reported
+		text = "0" + text
+	}
+	f, err := parseNumber(text)
+	if err != nil {
+		s.runtime.ErrorMessage(s.line, err.Error())
+	}
 	s.addToken(NumberTT, f)
 }
 
+func (s *Scanner) skipDigits() {
+	for unicode.IsDigit(s.peek()) || s.peek() == '_' {
+		s.advance()
+	}
+}
+
+// skipNumberTail consumes letters and digits following a number so that malformed literal like 0b12 or 1abc is reported as a whole.
+func (s *Scanner) skipNumberTail() {
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+}
+
+// radix returns base of numeric literal prefixed by 0 and c. It returns 0 if c isn't a prefix.
+func radix(c rune) int {
+	switch c {
+	case 'x', 'X':
+		return 16
+	case 'o', 'O':
+		return 8
+	case 'b', 'B':
+		return 2
+	}
+	return 0
+}
+
+// parseNumber parses numeric literal such as 123, 1.5e-3, 1_000, 0xFF, 0o17 and 0b1010
+func parseNumber(text string) (float64, error) {
+	invalid := fmt.Errorf("Invalid number literal '%s'.", text)
+	outOfRange := fmt.Errorf("Number literal '%s' is out of range.", text)
+
+	if len(text) > 1 && text[0] == '0' && radix(rune(text[1])) != 0 {
+		base := radix(rune(text[1]))
+		digits := text[2:]
+		if !validUnderscores(digits, base) {
+			return 0, invalid
+		}
+		n, err := strconv.ParseUint(strings.ReplaceAll(digits, "_", ""), base, 64)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return 0, outOfRange
+			}
+			return 0, invalid
+		}
+		return float64(n), nil
+	}
+
+	// ParseFloat alone accepts more than the literal, such as .5, inf and NaN
+	if !isDecimalLiteral(text) || !validUnderscores(text, 10) {
+		return 0, invalid
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, outOfRange
+		}
+		return 0, invalid
+	}
+	return f, nil
+}
+
+// isDecimalLiteral reports whether text is digits followed by optional fraction and exponent, such as 1_000.5e-3
+func isDecimalLiteral(text string) bool {
+	k := 0
+	digits := func() bool {
+		start := k
+		for k < len(text) && (isDigitOf(rune(text[k]), 10) || text[k] == '_') {
+			k++
+		}
+		return k > start
+	}
+
+	if !digits() {
+		return false
+	}
+	if k < len(text) && text[k] == '.' {
+		k++
+		if !digits() {
+			return false
+		}
+	}
+	if k < len(text) && (text[k] == 'e' || text[k] == 'E') {
+		k++
+		if k < len(text) && (text[k] == '+' || text[k] == '-') {
+			k++
+		}
+		if !digits() {
+			return false
+		}
+	}
+	return k == len(text)
+}
+
+// isDigitOf reports whether c is a digit in base
+func isDigitOf(c rune, base int) bool {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c-'0') < base
+	case 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		return base == 16
+	}
+	return false
+}
+
+// validUnderscores reports whether every underscore in text is placed between digits
+func validUnderscores(text string, base int) bool {
+	runes := []rune(text)
+	for k, c := range runes {
+		if c != '_' {
+			continue
+		}
+		if k == 0 || k == len(runes)-1 || !isDigitOf(runes[k-1], base) || !isDigitOf(runes[k+1], base) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Scanner) addIdentifier() {
 	for isAlphaNumeric(s.peek()) {
 		s.advance()
@@ -263,6 +589,21 @@ func (s *Scanner) isAtEnd() bool {
 	return s.source.Len() == 0
 }
 
+// isDecrement reports whether -- at the current position is postfix decrement.
+// It must follow a variable or property and must not be followed by an operand,
+// so that a--b is a - (-b) and --a is -(-a) as in jlox.
+func (s *Scanner) isDecrement() bool {
+	if len(s.tokens) == 0 || s.tokens[len(s.tokens)-1].Type != IdentifierTT {
+		return false
+	}
+	k := 1
+	for unicode.IsSpace(s.peekAt(k)) {
+		k++
+	}
+	next := s.peekAt(k)
+	return !isAlphaNumeric(next) && !strings.ContainsRune("([\"`!-~.", next)
+}
+
 func (s *Scanner) peek() rune {
 	if s.isAtEnd() {
 		return 0
@@ -271,55 +612,101 @@ func (s *Scanner) peek() rune {
 }
 
 func (s *Scanner) peekNext() rune {
-	if s.current+1 >= len(s.sourceRunes) {
+	return s.peekAt(1)
+}
+
+// peekAt returns the rune n runes after the current one
+func (s *Scanner) peekAt(n int) rune {
+	if s.current+n >= len(s.sourceRunes) {
 		return 0
 	}
-	return s.sourceRunes[s.current+1]
-}
-
-func (s *Scanner) handleEscapeCharacter() string {
-	runes := s.sourceRunes[s.start+1 : s.current-1]
-	value := make([]rune, 0)
-	var prevc rune
-	for _, v := range runes {
-		var c rune
-		if prevc == '\\' {
-			// https://en.wikipedia.org/wiki/C_syntax#Backslash_escapes
-			switch v {
-			case '\\':
-				c = v
-			case '"':
-				c = v
-			case 'n':
-				c = '\n'
-			case 'r':
-				c = '\r'
-			case 'b':
-				c = '\b'
-			case 't':
-				c = '\t'
-			case 'f':
-				c = '\f'
-			case 'v':
-				c = '\v'
-			default:
-				s.runtime.ErrorMessage(s.line, "invalid escape sequence")
-				return ""
-			}
+	return s.sourceRunes[s.current+n]
+}
+
+// unescape returns content of string literal whose escape sequences are replaced
+func (s *Scanner) unescape(runes []rune) string {
+	value := make([]rune, 0, len(runes))
+	for k := 0; k < len(runes); k++ {
+		if runes[k] != '\\' || k+1 >= len(runes) {
+			value = append(value, runes[k])
+			continue
+		}
 
-			if v == '\\' {
-				prevc = 0
+		k++
+		// https://en.wikipedia.org/wiki/C_syntax#Backslash_escapes
+		switch v := runes[k]; v {
+		case '\\', '"', '$':
+			value = append(value, v)
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 'b':
+			value = append(value, '\b')
+		case 't':
+			value = append(value, '\t')
+		case 'f':
+			value = append(value, '\f')
+		case 'v':
+			value = append(value, '\v')
+		case '0':
+			value = append(value, 0)
+		case 'x':
+			// \xHH
+			c, n, ok := hexRune(runes[k+1:], 2, 2)
+			if !ok {
+				s.runtime.ErrorMessage(s.line, "Invalid escape sequence '\\x"+string(runes[k+1:k+1+n])+"'; expect 2 hex digits.")
 			} else {
-				prevc = v
+				value = append(value, c)
 			}
-			value = append(value, c)
-		} else if v == '\\' {
-			prevc = v
-		} else {
-			prevc = v
-			value = append(value, v)
+			k += n
+		case 'u':
+			if k+1 < len(runes) && runes[k+1] == '{' {
+				// \u{H...}
+				c, n, ok := hexRune(runes[k+2:], 1, 6)
+				end := k + 2 + n
+				if !ok || end >= len(runes) || runes[end] != '}' {
+					s.runtime.ErrorMessage(s.line, "Invalid escape sequence '\\u{"+string(runes[k+2:end])+"'; expect 1 to 6 hex digits in braces.")
+					k = end - 1
+					break
+				}
+				value = s.appendCodePoint(value, c, string(runes[k-1:end+1]))
+				k = end
+				break
+			}
+			// \uHHHH
+			c, n, ok := hexRune(runes[k+1:], 4, 4)
+			if !ok {
+				s.runtime.ErrorMessage(s.line, "Invalid escape sequence '\\u"+string(runes[k+1:k+1+n])+"'; expect 4 hex digits.")
+			} else {
+				value = s.appendCodePoint(value, c, string(runes[k-1:k+1+n]))
+			}
+			k += n
+		default:
+			s.runtime.ErrorMessage(s.line, "Invalid escape sequence '\\"+string(v)+"'.")
 		}
 	}
 
 	return string(value)
 }
+
+// appendCodePoint appends code point c of escape sequence if it's a valid unicode scalar value
+func (s *Scanner) appendCodePoint(value []rune, c rune, sequence string) []rune {
+	if c > unicode.MaxRune || (0xD800 <= c && c <= 0xDFFF) {
+		s.runtime.ErrorMessage(s.line, "Invalid code point in escape sequence '"+sequence+"'.")
+		return value
+	}
+	return append(value, c)
+}
+
+// hexRune reads from min to max hex digits at the head of runes.
+// It returns the value, the number of digits read and whether enough digits are found.
+func hexRune(runes []rune, min, max int) (rune, int, bool) {
+	var c rune
+	n := 0
+	for n < max && n < len(runes) && isDigitOf(runes[n], 16) {
+		c = c*16 + rune(strings.IndexRune("0123456789abcdef", unicode.ToLower(runes[n])))
+		n++
+	}
+	return c, n, n >= min
+}
@@ -19,37 +19,37 @@ func TestScanner(t *testing.T) {
 		{
 			name: "assign val",
 			expected: golox.TokenList{
-				golox.NewToken(golox.IdentifierTT, "x", nil, 1),
-				golox.NewToken(golox.EqualTT, "=", nil, 1),
-				golox.NewToken(golox.NumberTT, "1", 1.0, 1),
-				golox.NewToken(golox.EOFTT, "", nil, 1),
+				tokenAt(golox.IdentifierTT, "x", nil, 1, 1),
+				tokenAt(golox.EqualTT, "=", nil, 1, 3),
+				tokenAt(golox.NumberTT, "1", 1.0, 1, 5),
+				tokenAt(golox.EOFTT, "", nil, 1, 6),
 			},
 			code: "x = 1",
 		},
 		{
 			name: "if block",
 			expected: golox.TokenList{
-				golox.NewToken(golox.IfTT, "if", nil, 1),
-				golox.NewToken(golox.IdentifierTT, "hoge", nil, 1),
-				golox.NewToken(golox.LeftBraceTT, "{", nil, 1),
-				golox.NewToken(golox.IdentifierTT, "x", nil, 1),
-				golox.NewToken(golox.SemicolonTT, ";", nil, 1),
-				golox.NewToken(golox.RightBraceTT, "}", nil, 1),
-				golox.NewToken(golox.ElseTT, "else", nil, 1),
-				golox.NewToken(golox.LeftBraceTT, "{", nil, 1),
-				golox.NewToken(golox.IfTT, "if", nil, 1),
-				golox.NewToken(golox.IdentifierTT, "piyo", nil, 1),
-				golox.NewToken(golox.LeftBraceTT, "{", nil, 1),
-				golox.NewToken(golox.IdentifierTT, "y", nil, 1),
-				golox.NewToken(golox.SemicolonTT, ";", nil, 1),
-				golox.NewToken(golox.RightBraceTT, "}", nil, 1),
-				golox.NewToken(golox.ElseTT, "else", nil, 1),
-				golox.NewToken(golox.LeftBraceTT, "{", nil, 1),
-				golox.NewToken(golox.IdentifierTT, "z", nil, 1),
-				golox.NewToken(golox.SemicolonTT, ";", nil, 1),
-				golox.NewToken(golox.RightBraceTT, "}", nil, 1),
-				golox.NewToken(golox.RightBraceTT, "}", nil, 1),
-				golox.NewToken(golox.EOFTT, "", nil, 1),
+				tokenAt(golox.IfTT, "if", nil, 1, 1),
+				tokenAt(golox.IdentifierTT, "hoge", nil, 1, 4),
+				tokenAt(golox.LeftBraceTT, "{", nil, 1, 9),
+				tokenAt(golox.IdentifierTT, "x", nil, 1, 11),
+				tokenAt(golox.SemicolonTT, ";", nil, 1, 12),
+				tokenAt(golox.RightBraceTT, "}", nil, 1, 14),
+				tokenAt(golox.ElseTT, "else", nil, 1, 16),
+				tokenAt(golox.LeftBraceTT, "{", nil, 1, 21),
+				tokenAt(golox.IfTT, "if", nil, 1, 23),
+				tokenAt(golox.IdentifierTT, "piyo", nil, 1, 26),
+				tokenAt(golox.LeftBraceTT, "{", nil, 1, 31),
+				tokenAt(golox.IdentifierTT, "y", nil, 1, 33),
+				tokenAt(golox.SemicolonTT, ";", nil, 1, 34),
+				tokenAt(golox.RightBraceTT, "}", nil, 1, 36),
+				tokenAt(golox.ElseTT, "else", nil, 1, 38),
+				tokenAt(golox.LeftBraceTT, "{", nil, 1, 43),
+				tokenAt(golox.IdentifierTT, "z", nil, 1, 45),
+				tokenAt(golox.SemicolonTT, ";", nil, 1, 46),
+				tokenAt(golox.RightBraceTT, "}", nil, 1, 48),
+				tokenAt(golox.RightBraceTT, "}", nil, 1, 50),
+				tokenAt(golox.EOFTT, "", nil, 1, 51),
 			},
 			code: "if hoge { x; } else { if piyo { y; } else { z; } }",
 			// if hoge {
@@ -65,22 +65,40 @@ func TestScanner(t *testing.T) {
 		{
 			name: "unicode string",
 			expected: golox.TokenList{
-				golox.NewToken(golox.IdentifierTT, "x", nil, 1),
-				golox.NewToken(golox.EqualTT, "=", nil, 1),
-				golox.NewToken(golox.StringTT, "\"hoge こんにちは\\\" piyo\"", "hoge こんにちは\" piyo", 1),
-				golox.NewToken(golox.EOFTT, "", nil, 1),
+				tokenAt(golox.IdentifierTT, "x", nil, 1, 1),
+				tokenAt(golox.EqualTT, "=", nil, 1, 3),
+				tokenAt(golox.StringTT, "\"hoge こんにちは\\\" piyo\"", "hoge こんにちは\" piyo", 1, 5),
+				tokenAt(golox.EOFTT, "", nil, 1, 24),
 			},
 			code: "x = \"hoge こんにちは\\\" piyo\"",
 		},
 		{
 			name: "useless newline",
 			expected: golox.TokenList{
-				golox.NewToken(golox.StringTT, "\"hoge\"", "hoge", 1),
-				golox.NewToken(golox.IdentifierTT, "piyo", nil, 1),
-				golox.NewToken(golox.EOFTT, "", nil, 1),
+				tokenAt(golox.StringTT, "\"hoge\"", "hoge", 3, 1),
+				tokenAt(golox.IdentifierTT, "piyo", nil, 5, 1),
+				tokenAt(golox.EOFTT, "", nil, 7, 15),
 			},
 			code: "\n\n\"hoge\"\n\npiyo // hogehoge\n// piyopiyo\n   // fugafuga",
 		},
+		{
+			name: "arithmetic and bitwise operators",
+			expected: golox.TokenList{
+				tokenAt(golox.PercentTT, "%", nil, 1, 1),
+				tokenAt(golox.StarStarTT, "**", nil, 1, 3),
+				tokenAt(golox.StarTT, "*", nil, 1, 6),
+				tokenAt(golox.TildeSlashTT, "~/", nil, 1, 8),
+				tokenAt(golox.TildeTT, "~", nil, 1, 11),
+				tokenAt(golox.AmpersandTT, "&", nil, 1, 13),
+				tokenAt(golox.PipeTT, "|", nil, 1, 15),
+				tokenAt(golox.CaretTT, "^", nil, 1, 17),
+				tokenAt(golox.LessLessTT, "<<", nil, 1, 19),
+				tokenAt(golox.GreaterGreaterTT, ">>", nil, 1, 22),
+				tokenAt(golox.LessEqualTT, "<=", nil, 1, 25),
+				tokenAt(golox.EOFTT, "", nil, 1, 27),
+			},
+			code: "% ** * ~/ ~ & | ^ << >> <=",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -93,3 +111,163 @@ func TestScanner(t *testing.T) {
 		})
 	}
 }
+
+func tokenAt(tt golox.TokenType, lexeme string, literal interface{}, line, column int) *golox.Token {
+	token := golox.NewToken(tt, lexeme, literal, line)
+	token.Column = column
+	return token
+}
+
+func TestScanner_Comments(t *testing.T) {
+	runtime := golox.NewRuntime()
+	buf := bytes.NewBufferString("// head\nx; // tail\n")
+	scanner := golox.NewScanner(runtime, buf)
+	tokens, comments := scanner.ScanTokensWithComments()
+
+	assert.Equal(t, golox.TokenList{
+		tokenAt(golox.IdentifierTT, "x", nil, 2, 1),
+		tokenAt(golox.SemicolonTT, ";", nil, 2, 2),
+		tokenAt(golox.EOFTT, "", nil, 3, 1),
+	}, tokens)
+	assert.Equal(t, golox.TokenList{
+		tokenAt(golox.CommentTT, "// head", nil, 1, 1),
+		tokenAt(golox.CommentTT, "// tail", nil, 2, 4),
+	}, comments)
+}
+
+func TestScanner_Number(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected float64
+		code     string
+	}{
+		{name: "integer", expected: 123, code: "123"},
+		{name: "fraction", expected: 123.45, code: "123.45"},
+		{name: "hexadecimal", expected: 255, code: "0xFF"},
+		{name: "lower case hexadecimal", expected: 255, code: "0xff"},
+		{name: "binary", expected: 10, code: "0b1010"},
+		{name: "octal", expected: 15, code: "0o17"},
+		{name: "exponent", expected: 0.0015, code: "1.5e-3"},
+		{name: "positive exponent", expected: 1e6, code: "1E+6"},
+		{name: "underscore", expected: 1000000, code: "1_000_000"},
+		{name: "underscore in hexadecimal", expected: 0xFFFF, code: "0xFF_FF"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stderr = stderr
+			tokens := golox.NewScanner(r, bytes.NewBufferString(tt.code)).ScanTokens()
+			assert.Equal(t, "", stderr.String())
+			assert.Equal(t, 2, len(tokens))
+			assert.Equal(t, tokenAt(golox.NumberTT, tt.code, tt.expected, 1, 1), tokens[0])
+		})
+	}
+}
+
+func TestScanner_NumberError(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		code     string
+	}{
+		{name: "leading dot", expected: "[line 1] Error: Number literal can't start with '.'; add a leading '0'.\n", code: ".5"},
+		{name: "invalid binary digit", expected: "[line 1] Error: Invalid number literal '0b102'.\n", code: "0b102"},
+		{name: "missing hexadecimal digits", expected: "[line 1] Error: Invalid number literal '0x'.\n", code: "0x"},
+		{name: "trailing underscore", expected: "[line 1] Error: Invalid number literal '1_'.\n", code: "1_"},
+		{name: "double underscores", expected: "[line 1] Error: Invalid number literal '1__0'.\n", code: "1__0"},
+		{name: "underscore before fraction", expected: "[line 1] Error: Invalid number literal '1_.5'.\n", code: "1_.5"},
+		{name: "letters after number", expected: "[line 1] Error: Invalid number literal '12abc'.\n", code: "12abc"},
+		{name: "missing exponent digits", expected: "[line 1] Error: Invalid number literal '1e'.\n", code: "1e"},
+		{name: "out of range", expected: "[line 1] Error: Number literal '1e999' is out of range.\n", code: "1e999"},
+		{name: "hexadecimal out of range", expected: "[line 1] Error: Number literal '0x1_0000_0000_0000_0000' is out of range.\n", code: "0x1_0000_0000_0000_0000"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stderr = stderr
+			golox.NewScanner(r, bytes.NewBufferString(tt.code)).ScanTokens()
+			assert.Equal(t, tt.expected, stderr.String())
+			assert.True(t, r.HadError)
+		})
+	}
+}
+
+func TestScanner_String(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		code     string
+	}{
+		{name: "escape", expected: "a\tb\n\"c\"\\", code: `"a\tb\n\"c\"\\"`},
+		{name: "null", expected: "a\x00b", code: `"a\0b"`},
+		{name: "hex", expected: "AZ\u00ff", code: `"\x41\x5a\xFF"`},
+		{name: "unicode", expected: "é日本", code: `"\u00e9\u65E5\u672c"`},
+		{name: "unicode in braces", expected: "A😀", code: `"\u{41}\u{1F600}"`},
+		{name: "raw string", expected: `^\d+\.\w*$`, code: "`^\\d+\\.\\w*$`"},
+		{name: "multi-line raw string", expected: "{\n  \"a\": \"\\n\"\n}", code: "`{\n  \"a\": \"\\n\"\n}`"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stderr = stderr
+			tokens := golox.NewScanner(r, bytes.NewBufferString(tt.code)).ScanTokens()
+			assert.Equal(t, "", stderr.String())
+			assert.Equal(t, 2, len(tokens))
+			assert.Equal(t, golox.StringTT, tokens[0].Type)
+			assert.Equal(t, tt.expected, tokens[0].Literal)
+		})
+	}
+}
+
+func TestScanner_StringError(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		code     string
+	}{
+		{name: "unknown escape", expected: "[line 1] Error: Invalid escape sequence '\\q'.\n", code: `"\q"`},
+		{name: "short hex", expected: "[line 1] Error: Invalid escape sequence '\\x4'; expect 2 hex digits.\n", code: `"\x4"`},
+		{name: "short unicode", expected: "[line 1] Error: Invalid escape sequence '\\u12'; expect 4 hex digits.\n", code: `"\u12G"`},
+		{name: "unclosed brace", expected: "[line 1] Error: Invalid escape sequence '\\u{41'; expect 1 to 6 hex digits in braces.\n", code: `"\u{41"`},
+		{name: "empty brace", expected: "[line 1] Error: Invalid escape sequence '\\u{'; expect 1 to 6 hex digits in braces.\n", code: `"\u{}"`},
+		{name: "too large code point", expected: "[line 1] Error: Invalid code point in escape sequence '\\u{110000}'.\n", code: `"\u{110000}"`},
+		{name: "surrogate", expected: "[line 1] Error: Invalid code point in escape sequence '\\uD800'.\n", code: `"\uD800"`},
+		{name: "multiple errors", expected: "[line 1] Error: Invalid escape sequence '\\q'.\n[line 1] Error: Invalid escape sequence '\\z'.\n", code: `"\q\z"`},
+		{name: "unterminated raw string", expected: "[line 2] Error: Unterminated raw string.\n", code: "`abc\n"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			r := golox.NewRuntime()
+			r.Stderr = stderr
+			golox.NewScanner(r, bytes.NewBufferString(tt.code)).ScanTokens()
+			assert.Equal(t, tt.expected, stderr.String())
+		})
+	}
+}
+
+func TestScanner_Interpolation(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	r := golox.NewRuntime()
+	r.Stderr = stderr
+	tokens := golox.NewScanner(r, bytes.NewBufferString("\"a${x}b${ {y} }\\${c}\"")).ScanTokens()
+	assert.Equal(t, "", stderr.String())
+	expected := golox.TokenList{
+		tokenAt(golox.InterpolationTT, "\"a${", "a", 1, 1),
+		tokenAt(golox.IdentifierTT, "x", nil, 1, 5),
+		tokenAt(golox.InterpolationTT, "}b${", "b", 1, 6),
+		tokenAt(golox.LeftBraceTT, "{", nil, 1, 11),
+		tokenAt(golox.IdentifierTT, "y", nil, 1, 12),
+		tokenAt(golox.RightBraceTT, "}", nil, 1, 13),
+		tokenAt(golox.StringTT, "}\\${c}\"", "${c}", 1, 15),
+		tokenAt(golox.EOFTT, "", nil, 1, 22),
+	}
+	assert.Equal(t, expected, tokens)
+}
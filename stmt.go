@@ -7,6 +7,7 @@ type Stmt interface {
 
 type VisitorStmt interface {
 	visitBlockStmt(*Block) (interface{}, error)
+	visitCaseStmt(*Case) (interface{}, error)
 	visitClassStmt(*Class) (interface{}, error)
 	visitExpressionStmt(*Expression) (interface{}, error)
 	visitFunctionStmt(*Function) (interface{}, error)
@@ -14,6 +15,7 @@ type VisitorStmt interface {
 	visitIncludeStmt(*Include) (interface{}, error)
 	visitPrintStmt(*Print) (interface{}, error)
 	visitReturnStmt(*Return) (interface{}, error)
+	visitSwitchStmt(*Switch) (interface{}, error)
 	visitVarStmt(*Var) (interface{}, error)
 	visitWhileStmt(*While) (interface{}, error)
 }
@@ -38,6 +40,28 @@ func (rec *Block) IsType(v interface{}) bool {
 	return false
 }
 
+type Case struct {
+	Keyword *Token
+	Values  []Expr
+	Body    []Stmt
+}
+
+func NewCase(keyword *Token, values []Expr, body []Stmt) Stmt {
+	return &Case{keyword, values, body}
+}
+
+func (c *Case) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.visitCaseStmt(c)
+}
+
+func (rec *Case) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Case:
+		return true
+	}
+	return false
+}
+
 type Class struct {
 	Name       *Token
 	Superclass *Variable
@@ -185,6 +209,28 @@ func (rec *Return) IsType(v interface{}) bool {
 	return false
 }
 
+type Switch struct {
+	Keyword *Token
+	Value   Expr
+	Cases   []*Case
+}
+
+func NewSwitch(keyword *Token, value Expr, cases []*Case) Stmt {
+	return &Switch{keyword, value, cases}
+}
+
+func (s *Switch) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.visitSwitchStmt(s)
+}
+
+func (rec *Switch) IsType(v interface{}) bool {
+	switch v.(type) {
+	case *Switch:
+		return true
+	}
+	return false
+}
+
 type Var struct {
 	Name        *Token
 	Initializer Expr
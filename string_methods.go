@@ -0,0 +1,125 @@
+package golox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stringMethods are methods of string. Indexes and lengths count runes, not bytes.
+var stringMethods = map[string]struct {
+	arity    int
+	function func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error)
+}{
+	"len": {0, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		return float64(len([]rune(s))), nil
+	}},
+	"upper": {0, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		return strings.ToUpper(s), nil
+	}},
+	"lower": {0, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		return strings.ToLower(s), nil
+	}},
+	"trim": {0, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		return strings.TrimSpace(s), nil
+	}},
+	"split": {1, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		sep, err := bm.stringArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		return stringList(strings.Split(s, sep)), nil
+	}},
+	"chars": {0, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		return stringList(strings.Split(s, "")), nil
+	}},
+	"contains": {1, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		sub, err := bm.stringArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(s, sub), nil
+	}},
+	"startsWith": {1, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		prefix, err := bm.stringArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasPrefix(s, prefix), nil
+	}},
+	"endsWith": {1, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		suffix, err := bm.stringArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasSuffix(s, suffix), nil
+	}},
+	"indexOf": {1, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		sub, err := bm.stringArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		k := strings.Index(s, sub)
+		if k < 0 {
+			return float64(-1), nil
+		}
+		return float64(len([]rune(s[:k]))), nil
+	}},
+	"replace": {2, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		old, err := bm.stringArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		new, err := bm.stringArgument(arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(s, old, new), nil
+	}},
+	"substring": {2, func(bm *BuiltinMethod, s string, arguments []interface{}) (interface{}, error) {
+		start, err := bm.integerArgument(arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		end, err := bm.integerArgument(arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		runes := []rune(s)
+		if start < 0 || end < start || end > len(runes) {
+			return nil, RuntimeError.New(nil, fmt.Sprintf("Substring range [%d, %d) is out of range for length %d.", start, end, len(runes)))
+		}
+		return string(runes[start:end]), nil
+	}},
+}
+
+// stringMethod returns method of s bound to s
+func stringMethod(s string, name *Token) (interface{}, error) {
+	method, ok := stringMethods[name.Lexeme]
+	if !ok {
+		return nil, RuntimeError.New(name, "Undefined property '"+name.Lexeme+"'.")
+	}
+
+	bm := &BuiltinMethod{name: name.Lexeme, arity: method.arity}
+	bm.function = func(i *Interpreter, arguments []interface{}) (interface{}, error) {
+		return method.function(bm, s, arguments)
+	}
+	return bm, nil
+}
+
+// indexString returns k-th rune of s as string
+func indexString(s string, bracket *Token, index interface{}) (interface{}, error) {
+	runes := []rune(s)
+	k, err := checkIndex(bracket, index, len(runes))
+	if err != nil {
+		return nil, err
+	}
+	return string(runes[k]), nil
+}
+
+func stringList(elements []string) *GoLoxList {
+	list := NewGoLoxList(make([]interface{}, 0, len(elements)))
+	for _, element := range elements {
+		list.Elements = append(list.Elements, element)
+	}
+	return list
+}
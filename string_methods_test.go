@@ -0,0 +1,67 @@
+package golox_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringMethods(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "len counts runes", expected: "5\n", given: `print "日本語ab".len();`},
+		{name: "upper", expected: "ABC\n", given: `print "abc".upper();`},
+		{name: "lower", expected: "abc\n", given: `print "ABC".lower();`},
+		{name: "trim", expected: "a b\n", given: `print " \ta b\n".trim();`},
+		{name: "split", expected: "[\"a\", \"\", \"b\"]\n", given: `print "a,,b".split(",");`},
+		{name: "chars", expected: "[\"日\", \"本\"]\n", given: `print "日本".chars();`},
+		{name: "contains", expected: "true\nfalse\n", given: `print "abc".contains("bc"); print "abc".contains("d");`},
+		{name: "startsWith", expected: "true\n", given: `print "abc".startsWith("ab");`},
+		{name: "endsWith", expected: "true\n", given: `print "abc".endsWith("bc");`},
+		{name: "indexOf counts runes", expected: "2\n-1\n", given: `print "日本語".indexOf("語"); print "abc".indexOf("d");`},
+		{name: "replace", expected: "a-b-c\n", given: `print "a b c".replace(" ", "-");`},
+		{name: "substring", expected: "本語\n", given: `print "日本語".substring(1, 3);`},
+		{name: "method value", expected: "ABC\n", given: `var f = "abc".upper; print f();`},
+		{name: "index", expected: "語\n", given: `print "日本語"[2];`},
+		{name: "index in loop", expected: "a\nb\n", given: `var s = "ab"; for (var i = 0; i < s.len(); i = i + 1) print s[i];`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runScript(tt.given)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, tt.expected, stdout)
+		})
+	}
+}
+
+func TestStringMethods_Error(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+		given    string
+	}{
+		{name: "undefined method", expected: "Undefined property 'foo'.\n[line 1]\n", given: `"abc".foo();`},
+		{name: "argument type", expected: "Argument 1 of split must be a string.\n[line 1]\n", given: `"abc".split(1);`},
+		{name: "arity", expected: "Expected 2 arguments but got 1.\n[line 1]\n", given: `"abc".replace("a");`},
+		{name: "substring out of range", expected: "Substring range [1, 4) is out of range for length 3.\n[line 1]\n", given: `"abc".substring(1, 4);`},
+		{name: "substring with fraction", expected: "Argument 2 of substring must be an integer.\n[line 1]\n", given: `"abc".substring(0, 1.5);`},
+		{name: "index out of range", expected: "Index 3 is out of range for length 3.\n[line 1]\n", given: `"abc"[3];`},
+		{name: "substring beyond int", expected: "Argument 1 of substring is out of range: -100000000000000000000.\n[line 1]\n", given: `"abc".substring(-1e20, 2);`},
+		{name: "index with string", expected: "Index must be an integer.\n[line 1]\n", given: `"abc"["a"];`},
+		{name: "assign to index", expected: "Only list elements can be assigned.\n[line 1]\n", given: `var s = "abc"; s[0] = "x";`},
+		{name: "index number", expected: "Only lists and strings can be indexed.\n[line 1]\n", given: `1[0];`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := runScript(tt.given)
+			assert.Equal(t, tt.expected, stderr)
+		})
+	}
+}
@@ -1,10 +1,21 @@
 #!/bin/bash
+# Usage: COVERAGE=coverage.lcov bash test/test.sh
+# If COVERAGE is set, lcov reports of all scripts are written to the file.
 
 set -e
 
 TEST_DIR=$(cd $(dirname $0); pwd)
+if [ -n "${COVERAGE}" ]; then
+    : > ${COVERAGE}
+fi
 for i in $(ls ${TEST_DIR}/*.lox); do
     echo $i
-    ./golox $i
+    if [ -n "${COVERAGE}" ]; then
+        ./golox --coverage ${COVERAGE}.tmp $i
+        cat ${COVERAGE}.tmp >> ${COVERAGE}
+        rm ${COVERAGE}.tmp
+    else
+        ./golox $i
+    fi
     echo pass
 done
@@ -0,0 +1,207 @@
+package golox
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// testPrefix is prefix of names of test functions
+const testPrefix = "test_"
+
+// TestResult is result of a test function
+type TestResult struct {
+	File string
+	Name string
+	// Line is where the test failed. It is the line of test function if the test passed.
+	Line     int
+	Passed   bool
+	Message  string
+	Output   string
+	Duration time.Duration
+}
+
+// RunTestFile runs test_* functions declared at top level of the file.
+// Each test runs in a fresh Runtime after top-level code of the file is executed.
+// If the file has a syntax error, a failed result named after the file is returned.
+func RunTestFile(path string) ([]*TestResult, error) {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, _ := newTestRuntime(path)
+	statements := r.Parse(bytes.NewBuffer(source))
+	if r.HadError {
+		return []*TestResult{loadFailure(path, r)}, nil
+	}
+
+	results := make([]*TestResult, 0)
+	for _, stmt := range statements {
+		function, ok := stmt.(*Function)
+		if !ok || !strings.HasPrefix(function.Name.Lexeme, testPrefix) {
+			continue
+		}
+		results = append(results, runTest(path, source, function.Name))
+	}
+	return results, nil
+}
+
+func runTest(path string, source []byte, name *Token) *TestResult {
+	result := &TestResult{File: path, Name: name.Lexeme, Line: name.Line}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	r, stdout := newTestRuntime(path)
+	defer func() { result.Output = stdout.String() }()
+
+	statements := r.Parse(bytes.NewBuffer(source))
+	interpreter := NewInterpreter(r)
+	NewResolver(r, interpreter).ResolveStmts(statements)
+	if r.HadError {
+		failure := loadFailure(path, r)
+		result.Line, result.Message = failure.Line, failure.Message
+		return result
+	}
+
+	if _, err := interpreter.Interpret(statements); err != nil {
+		result.fail(err)
+		return result
+	}
+
+	function, ok := r.Globals.Values[name.Lexeme].(GoLoxCallable)
+	if !ok {
+		result.Message = "'" + name.Lexeme + "' isn't a function."
+		return result
+	}
+	if function.Arity() != 0 {
+		result.Message = "Test function must not take parameters."
+		return result
+	}
+	if _, err := function.Call(interpreter, nil); err != nil {
+		result.fail(err)
+		return result
+	}
+
+	result.Passed = true
+	return result
+}
+
+// newTestRuntime returns a runtime with assertions. Its output is captured.
+func newTestRuntime(path string) (*Runtime, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	r := NewRuntime()
+	r.Stdout = stdout
+	r.Stderr = ioutil.Discard
+	r.BasePath = filepath.Dir(path)
+	r.File = path
+	r.DefineAssertions()
+	return r, stdout
+}
+
+func (tr *TestResult) fail(err error) {
+	tr.Message = err.Error()
+	if e, ok := err.(*CustomError); ok && e.Token != nil {
+		tr.Line = e.Token.Line
+	}
+}
+
+// loadFailure returns failed result of the file which has syntax or resolution errors
+func loadFailure(path string, r *Runtime) *TestResult {
+	messages := make([]string, 0)
+	for _, diag := range r.Diagnostics {
+		where := ""
+		if diag.Lexeme != "" {
+			where = " at '" + diag.Lexeme + "'"
+		}
+		messages = append(messages, fmt.Sprintf("[line %d] Error%s: %s", diag.Line, where, diag.Message))
+	}
+	return &TestResult{
+		File:    path,
+		Name:    filepath.Base(path),
+		Line:    r.Diagnostics[0].Line,
+		Message: strings.Join(messages, "\n"),
+	}
+}
+
+// junitTestSuites is root element of JUnit XML
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results in JUnit XML format. Each file is a test suite.
+func WriteJUnit(w io.Writer, results []*TestResult) error {
+	root := &junitTestSuites{}
+	suites := make(map[string]*junitTestSuite)
+	durations := make(map[string]time.Duration)
+	for _, result := range results {
+		suite, ok := suites[result.File]
+		if !ok {
+			suite = &junitTestSuite{Name: result.File}
+			suites[result.File] = suite
+			root.Suites = append(root.Suites, suite)
+		}
+
+		testCase := &junitTestCase{
+			Name:      result.Name,
+			Classname: strings.TrimSuffix(filepath.Base(result.File), ".lox"),
+			Time:      seconds(result.Duration),
+			SystemOut: result.Output,
+		}
+		if !result.Passed {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: result.Message,
+				Text:    fmt.Sprintf("%s:%d: %s", result.File, result.Line, result.Message),
+			}
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+		durations[result.File] += result.Duration
+	}
+	for file, suite := range suites {
+		suite.Time = seconds(durations[file])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
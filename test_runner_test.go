@@ -0,0 +1,172 @@
+package golox_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goropikari/golox"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFile = `var counter = 0;
+
+fun test_pass() {
+  counter = counter + 1;
+  assertEqual(1, counter);
+}
+
+fun test_isolated() {
+  counter = counter + 1;
+  assertEqual(1, counter);
+}
+
+fun test_assert_equal() {
+  print "output";
+  assertEqual("1", 1);
+}
+
+fun test_assert() {
+  assert(nil);
+}
+
+fun test_assert_throws() {
+  fun ok() { return 1; }
+  assertThrows(ok);
+}
+
+fun test_runtime_error() {
+  assertThrows(fun_with_error);
+  fun_with_error();
+}
+
+fun fun_with_error() {
+  return -"a";
+}
+
+fun test_native_error() {
+  exit("a");
+}
+
+fun test_params(a) {}
+
+fun helper() {
+  assert(false);
+}
+`
+
+func writeTestFile(t *testing.T, name, source string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(source), 0644))
+	return path
+}
+
+func TestRunTestFile(t *testing.T) {
+	path := writeTestFile(t, "sample_test.lox", testFile)
+
+	results, err := golox.RunTestFile(path)
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		expected golox.TestResult
+	}{
+		{
+			name:     "test_pass",
+			expected: golox.TestResult{Name: "test_pass", Line: 3, Passed: true},
+		},
+		{
+			name:     "each test runs in fresh runtime",
+			expected: golox.TestResult{Name: "test_isolated", Line: 8, Passed: true},
+		},
+		{
+			name:     "assertEqual",
+			expected: golox.TestResult{Name: "test_assert_equal", Line: 15, Message: `AssertionError: Expected "1" but got 1.`, Output: "output\n"},
+		},
+		{
+			name:     "assert",
+			expected: golox.TestResult{Name: "test_assert", Line: 19, Message: "AssertionError: Assertion failed."},
+		},
+		{
+			name:     "assertThrows",
+			expected: golox.TestResult{Name: "test_assert_throws", Line: 24, Message: "AssertionError: Expected a runtime error but nothing was thrown."},
+		},
+		{
+			name:     "runtime error",
+			expected: golox.TestResult{Name: "test_runtime_error", Line: 33, Message: "RuntimeError: Operand must be a number."},
+		},
+		{
+			name:     "error of native function is reported at call site",
+			expected: golox.TestResult{Name: "test_native_error", Line: 37, Message: "RuntimeError: invalid type"},
+		},
+		{
+			name:     "test function with parameters",
+			expected: golox.TestResult{Name: "test_params", Line: 40, Message: "Test function must not take parameters."},
+		},
+	}
+
+	assert.Equal(t, len(tests), len(results))
+	for k, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := results[k]
+			assert.Equal(t, path, actual.File)
+			actual.File = ""
+			actual.Duration = 0
+			assert.Equal(t, tt.expected, *actual)
+		})
+	}
+}
+
+func TestRunTestFile_Error(t *testing.T) {
+	t.Run("syntax error", func(t *testing.T) {
+		path := writeTestFile(t, "syntax_test.lox", "fun test_a() {\n  var = 1;\n}\n")
+		results, err := golox.RunTestFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, "syntax_test.lox", results[0].Name)
+		assert.Equal(t, 2, results[0].Line)
+		assert.Equal(t, "[line 2] Error at '=': Expect variable name.", results[0].Message)
+	})
+
+	t.Run("error in top-level code", func(t *testing.T) {
+		path := writeTestFile(t, "setup_test.lox", "fun test_a() {}\nvar a = nil + 1;\n")
+		results, err := golox.RunTestFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(results))
+		assert.False(t, results[0].Passed)
+		assert.Equal(t, 2, results[0].Line)
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		_, err := golox.RunTestFile(filepath.Join(t.TempDir(), "nothing_test.lox"))
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []*golox.TestResult{
+		{File: "a_test.lox", Name: "test_ok", Line: 1, Passed: true, Duration: 1500 * time.Microsecond},
+		{File: "a_test.lox", Name: "test_ng", Line: 5, Message: "AssertionError: Assertion failed.", Output: "<out>\n", Duration: time.Millisecond},
+		{File: "b_test.lox", Name: "test_b", Line: 2, Passed: true},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, golox.WriteJUnit(buf, results))
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="a_test.lox" tests="2" failures="1" time="0.003">
+    <testcase name="test_ok" classname="a_test" time="0.002"></testcase>
+    <testcase name="test_ng" classname="a_test" time="0.001">
+      <failure message="AssertionError: Assertion failed.">a_test.lox:5: AssertionError: Assertion failed.</failure>
+      <system-out>&lt;out&gt;&#xA;</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="b_test.lox" tests="1" failures="0" time="0.000">
+    <testcase name="test_b" classname="b_test" time="0.000"></testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, buf.String())
+}
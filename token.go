@@ -1,6 +1,9 @@
 package golox
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // TokenType is type of type
 type TokenType int
@@ -11,6 +14,8 @@ const (
 	RightParenTT
 	LeftBraceTT
 	RightBraceTT
+	LeftBracketTT
+	RightBracketTT
 	CommaTT
 	DotTT
 	MinusTT
@@ -18,6 +23,13 @@ const (
 	SemicolonTT
 	SlashTT
 	StarTT
+	PercentTT
+	AmpersandTT
+	PipeTT
+	CaretTT
+	TildeTT
+	QuestionTT
+	ColonTT
 
 	// One or two chacacter tokens
 	BangTT
@@ -29,15 +41,33 @@ const (
 	GreaterEqualTT
 	LessTT
 	LessEqualTT
+	LessLessTT
+	GreaterGreaterTT
+	StarStarTT
+	// TildeSlashTT is integer division
+	TildeSlashTT
+	PlusEqualTT
+	MinusEqualTT
+	StarEqualTT
+	SlashEqualTT
+	PlusPlusTT
+	MinusMinusTT
+	QuestionQuestionTT
+	QuestionDotTT
+	ArrowTT
 
 	// Literal
 	IdentifierTT
 	StringTT
+	// InterpolationTT is part of string literal before ${
+	InterpolationTT
 	NumberTT
 
 	// keywords
 	AndTT
+	CaseTT
 	ClassTT
+	DefaultTT
 	ElseTT
 	ElseifTT
 	FalseTT
@@ -45,16 +75,21 @@ const (
 	ForTT
 	IfTT
 	IncludeTT
+	MatchTT
 	NilTT
 	OrTT
 	PrintTT
 	ReturnTT
 	SuperTT
+	SwitchTT
 	ThisTT
 	TrueTT
 	VarTT
 	WhileTT
 
+	// trivia
+	CommentTT
+
 	EOFTT
 )
 
@@ -64,6 +99,7 @@ type Token struct {
 	Lexeme  string
 	Literal interface{}
 	Line    int
+	Column  int
 }
 
 // TokenList is slice of Token
@@ -83,3 +119,13 @@ func NewToken(tt TokenType, lexeme string, literal interface{}, line int) *Token
 func (t *Token) String() string {
 	return fmt.Sprintf("%v\t%v\t%v\t%v", t.Type, t.Lexeme, t.Literal, t.Line)
 }
+
+// isRawString reports whether token is a string literal enclosed in backquotes
+func isRawString(token *Token) bool {
+	return token != nil && token.Type == StringTT && strings.HasPrefix(token.Lexeme, "`")
+}
+
+// closesInterpolation reports whether token is the rest of string after '}' of ${...}
+func closesInterpolation(token *Token) bool {
+	return (token.Type == StringTT || token.Type == InterpolationTT) && strings.HasPrefix(token.Lexeme, "}")
+}
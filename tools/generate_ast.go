@@ -17,11 +17,20 @@ func main() {
 		"Assign : name *Token, value Expr",
 		"Binary : left Expr, operator *Token, right Expr",
 		"Call : callee Expr, paren *Token, arguments []Expr",
-		"Get : object Expr, name *Token",
+		"CompoundAssign : target Expr, operator *Token, value Expr, postfix bool",
+		"Conditional : condition Expr, thenBranch Expr, elseBranch Expr",
+		"Get : object Expr, name *Token, optional bool",
 		"Grouping : expression Expr",
+		"Index : object Expr, bracket *Token, index Expr",
+		"Interpolation : parts []Expr",
+		"Lambda : keyword *Token, function *Function",
+		"List : bracket *Token, elements []Expr",
 		"Literal : value interface{}",
 		"Logical : left Expr, operator *Token, right Expr",
+		"Match : keyword *Token, value Expr, arms []*MatchArm",
+		"OptionalChain : expression Expr",
 		"Set : object Expr, name *Token, value Expr",
+		"SetIndex : object Expr, bracket *Token, index Expr, value Expr",
 		"Super : keyword *Token, method *Token",
 		"This : keyword *Token",
 		"Unary : operator *Token, right Expr",
@@ -30,6 +39,7 @@ func main() {
 
 	defineAst(outputDir, "Stmt", []string{
 		"Block : statements []Stmt",
+		"Case : keyword *Token, values []Expr, body []Stmt",
 		"Class : name *Token, superclass *Variable, methods []*Function",
 		"Expression: expression Expr",
 		"Function : name *Token, params []*Token, body []Stmt",
@@ -37,6 +47,7 @@ func main() {
 		"Include : path *Token",
 		"Print : expression Expr",
 		"Return : keyword *Token, value Expr",
+		"Switch : keyword *Token, value Expr, cases []*Case",
 		"Var : name *Token, initializer Expr",
 		"While : condition Expr, body Stmt",
 	})
@@ -0,0 +1,18 @@
+package golox
+
+// Tracer observes execution of a program.
+// Tracers registered by Runtime.AddTracer are notified by Interpreter.
+type Tracer interface {
+	// Statement is called before stmt is executed.
+	// If it returns an error, the statement isn't executed and the error is propagated.
+	Statement(i *Interpreter, stmt Stmt) error
+	// Call is called before body of the Lox function is executed
+	Call(i *Interpreter, function *GoLoxFunction)
+	// Return is called after the Lox function returns
+	Return(i *Interpreter, function *GoLoxFunction)
+}
+
+// AddTracer registers the tracer to runtime
+func (r *Runtime) AddTracer(t Tracer) {
+	r.Tracers = append(r.Tracers, t)
+}